@@ -0,0 +1,297 @@
+package gotaskflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Analysis is the critical path analysis of a traced run, see Analyze.
+type Analysis struct {
+	CriticalPath []PathStep               // task executions that determined the latency of the run, in order
+	Slack        map[string]time.Duration // how long each task could have been delayed without delaying the run, keyed by Path
+	Wall         time.Duration            // wall time of the run
+	Work         time.Duration            // sum of the wall time of every task execution
+	Workers      int                      // workers the run could use
+	Efficiency   float64                  // Work / (Wall × Workers)
+
+	critical      map[*innerNode]bool
+	criticalEdges map[[2]*innerNode]bool
+}
+
+// PathStep is one task execution on the critical path.
+type PathStep struct {
+	Path     string        // task name, prefixed with the names of its enclosing subflows, e.g. "sub/task"
+	Start    time.Duration // since the run started
+	Duration time.Duration // wall time, for subflows the time to instantiate them
+}
+
+// Analyze computes the critical path of a run of tf from its Chrome trace, as written by Executor.Trace
+// for an executor that ran tf once. workers is the concurrency of that executor; when 0, the number of
+// worker rows found in the trace is used.
+func Analyze(trace io.Reader, tf *TaskFlow, workers int) (*Analysis, error) {
+	events, err := readTrace(trace)
+	if err != nil {
+		return nil, err
+	}
+	return analyze(tasksOf(events), tf, workers), nil
+}
+
+// readTrace decodes the events written by Executor.Trace.
+func readTrace(trace io.Reader) ([]chromeTraceEvent, error) {
+	var events []chromeTraceEvent
+	if err := json.NewDecoder(trace).Decode(&events); err != nil {
+		return nil, fmt.Errorf("decode trace -> %w", err)
+	}
+	return events, nil
+}
+
+// tasksOf returns the task execution events of a trace.
+func tasksOf(events []chromeTraceEvent) traceRecord {
+	rec := make(traceRecord, 0, len(events))
+	for _, ev := range events {
+		if ev.Ph == "X" {
+			rec = append(rec, ev)
+		}
+	}
+	return rec
+}
+
+// execution is one task execution of a traceRecord, linked to the executions it waited for.
+type execution struct {
+	ev         chromeTraceEvent
+	node       *innerNode // nil if tf has no such task
+	path       string
+	parent     *execution // execution of the enclosing subflow
+	start, end int64      // µs, end includes the tasks of a subflow
+	preds      []*execution
+	succs      []*execution
+	children   []*execution
+
+	lf     int64 // latest finish without delaying the run
+	lfDone bool
+}
+
+type execScope struct {
+	parent *execution
+	name   string
+}
+
+func analyze(rec traceRecord, tf *TaskFlow, workers int) *Analysis {
+	a := &Analysis{
+		Slack:         make(map[string]time.Duration),
+		Workers:       workers,
+		critical:      make(map[*innerNode]bool),
+		criticalEdges: make(map[[2]*innerNode]bool),
+	}
+	if len(rec) == 0 {
+		return a
+	}
+
+	execs := newExecutions(rec, tf)
+	byScope := make(map[execScope][]*execution)
+	for _, e := range execs {
+		byScope[execScope{e.parent, e.ev.Name}] = append(byScope[execScope{e.parent, e.ev.Name}], e)
+	}
+
+	// children start after their subflow, so visiting by descending start settles them first
+	for i := len(execs) - 1; i >= 0; i-- {
+		if e := execs[i]; e.parent != nil && e.end > e.parent.end {
+			e.parent.end = e.end
+		}
+	}
+
+	for _, e := range execs {
+		for _, dep := range dependentsOf(e) {
+			var pred *execution
+			for _, c := range byScope[execScope{e.parent, dep}] {
+				if c.end <= e.start && (pred == nil || c.end > pred.end) {
+					pred = c
+				}
+			}
+			if pred != nil {
+				e.preds = append(e.preds, pred)
+				pred.succs = append(pred.succs, e)
+			}
+		}
+	}
+
+	begin, finish := execs[0].start, execs[0].end
+	rows := make(map[int64]bool)
+	var last *execution
+	for _, e := range execs {
+		a.Work += time.Duration(e.ev.Dur) * time.Microsecond
+		if e.ev.Tid < workerTidBase {
+			rows[e.ev.Tid] = true
+		}
+		if e.parent == nil && (last == nil || e.end > last.end) {
+			last = e
+		}
+		if e.end > finish {
+			finish = e.end
+		}
+	}
+	a.Wall = time.Duration(finish-begin) * time.Microsecond
+	if a.Workers == 0 {
+		a.Workers = len(rows)
+	}
+	if a.Wall > 0 && a.Workers > 0 {
+		a.Efficiency = float64(a.Work) / (float64(a.Wall) * float64(a.Workers))
+	}
+
+	a.criticalPath(last, begin)
+	for _, e := range execs {
+		slack := time.Duration(latestFinish(e, finish)-e.end) * time.Microsecond
+		if slack < 0 {
+			slack = 0
+		}
+		if s, ok := a.Slack[e.path]; !ok || slack < s {
+			a.Slack[e.path] = slack
+		}
+	}
+	return a
+}
+
+// newExecutions returns the executions of rec ordered by start, matched with their enclosing
+// subflow execution and with the nodes of tf.
+func newExecutions(rec traceRecord, tf *TaskFlow) []*execution {
+	execs := make([]*execution, len(rec))
+	for i, ev := range rec {
+		execs[i] = &execution{ev: ev, start: ev.Ts, end: ev.Ts + ev.Dur}
+	}
+	sort.SliceStable(execs, func(i, j int) bool { return execs[i].start < execs[j].start })
+
+	nodes := make(map[string]*innerNode)
+	if tf != nil {
+		indexNodes(tf.graph, "", nodes)
+	}
+	for i, e := range execs {
+		// traces carry the path of tasks, older ones only the name of their subflow
+		qualified, hasPath := e.ev.Args["path"]
+		parent := func(c *execution) bool { return c.ev.Name == e.ev.Args["parent"] }
+		if hasPath {
+			dir := qualified[:max(strings.LastIndex(qualified, "/"), 0)]
+			parent = func(c *execution) bool { return c.ev.Args["path"] == dir }
+		}
+		if e.ev.Args["parent"] != "" {
+			for j := i - 1; j >= 0; j-- {
+				if execs[j].ev.Cat == string(nodeSubflow) && parent(execs[j]) {
+					e.parent = execs[j]
+					break
+				}
+			}
+		}
+		e.path = e.ev.Name
+		if e.parent != nil {
+			e.path = e.parent.path + "/" + e.ev.Name
+			e.parent.children = append(e.parent.children, e)
+		}
+		e.node = nodes[e.path]
+	}
+	return execs
+}
+
+// indexNodes maps the path of every task of g to its node.
+func indexNodes(g *eGraph, prefix string, nodes map[string]*innerNode) {
+	for _, n := range g.nodes {
+		nodes[prefix+n.name] = n
+		if sf, ok := n.ptr.(*Subflow); ok && sf.g != nil && sf.g.instantiated {
+			indexNodes(sf.g, prefix+n.name+"/", nodes)
+		}
+	}
+}
+
+func dependentsOf(e *execution) []string {
+	if e.node != nil {
+		return getDependentNames(e.node)
+	}
+	if raw := e.ev.Args["dependents"]; raw != "" {
+		return strings.Split(raw, ",")
+	}
+	return nil
+}
+
+func latest(execs []*execution) *execution {
+	var l *execution
+	for _, e := range execs {
+		if l == nil || e.end > l.end {
+			l = e
+		}
+	}
+	return l
+}
+
+// criticalPath walks back from the execution that finished last, following at every step the
+// predecessor that finished last, into subflows through their last finished task.
+func (a *Analysis) criticalPath(last *execution, begin int64) {
+	descend := func(e *execution) *execution {
+		for len(e.children) > 0 {
+			e = latest(e.children)
+		}
+		return e
+	}
+
+	cur := descend(last)
+	steps := []*execution{cur}
+	for {
+		if p := latest(cur.preds); p != nil {
+			if p.node != nil && cur.node != nil {
+				a.criticalEdges[[2]*innerNode{p.node, cur.node}] = true
+			}
+			cur = descend(p)
+		} else if cur.parent != nil {
+			cur = cur.parent
+		} else {
+			break
+		}
+		steps = append(steps, cur)
+	}
+
+	for i := len(steps) - 1; i >= 0; i-- {
+		e := steps[i]
+		a.CriticalPath = append(a.CriticalPath, PathStep{
+			Path:     e.path,
+			Start:    time.Duration(e.start-begin) * time.Microsecond,
+			Duration: time.Duration(e.ev.Dur) * time.Microsecond,
+		})
+		if e.node != nil {
+			a.critical[e.node] = true
+		}
+	}
+}
+
+// latestFinish returns the latest time e could have finished without delaying its
+// successors, its enclosing subflow or, at the top level, the end of the run.
+func latestFinish(e *execution, finish int64) int64 {
+	if e.lfDone {
+		return e.lf
+	}
+	lf := finish
+	if e.parent != nil {
+		lf = latestFinish(e.parent, finish)
+	}
+	for _, s := range e.succs {
+		if ls := latestFinish(s, finish) - (s.end - s.start); ls < lf {
+			lf = ls
+		}
+	}
+	e.lf, e.lfDone = lf, true
+	return lf
+}
+
+// Visualizer returns a Visualizer writing tf in DOT format with the critical path highlighted.
+func (a *Analysis) Visualizer() Visualizer {
+	return &dotVizer{critical: a.critical, criticalEdges: a.criticalEdges}
+}
+
+func (a *Analysis) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("wall %v, work %v, %d workers, efficiency %.2f\ncritical path:\n", a.Wall, a.Work, a.Workers, a.Efficiency))
+	for _, s := range a.CriticalPath {
+		sb.WriteString(fmt.Sprintf("  %v +%v %s\n", s.Start, s.Duration, s.Path))
+	}
+	return sb.String()
+}
@@ -0,0 +1,70 @@
+package gotaskflow
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeCriticalPath(t *testing.T) {
+	tf := NewTaskFlow("G")
+	A, B, C, D :=
+		tf.NewTask("A", func() {}),
+		tf.NewTask("B", func() {}),
+		tf.NewTask("C", func() {}),
+		tf.NewTask("D", func() {})
+	A.Precede(B, C)
+	B.Precede(D)
+	C.Precede(D)
+
+	ms := func(n int64) int64 { return n * 1000 }
+	rec := traceRecord{
+		{Name: "A", Cat: "static", Ph: "X", Ts: ms(0), Dur: ms(10), Tid: 1},
+		{Name: "B", Cat: "static", Ph: "X", Ts: ms(10), Dur: ms(30), Tid: 1},
+		{Name: "C", Cat: "static", Ph: "X", Ts: ms(10), Dur: ms(10), Tid: 2},
+		{Name: "D", Cat: "static", Ph: "X", Ts: ms(40), Dur: ms(10), Tid: 1},
+	}
+	a := analyze(rec, tf, 0)
+
+	var path []string
+	for _, s := range a.CriticalPath {
+		path = append(path, s.Path)
+	}
+	if strings.Join(path, ",") != "A,B,D" {
+		t.Errorf("expected critical path A,B,D, got %v", path)
+	}
+	if a.CriticalPath[1].Start != 10*time.Millisecond || a.CriticalPath[1].Duration != 30*time.Millisecond {
+		t.Errorf("unexpected step %+v", a.CriticalPath[1])
+	}
+	for name, want := range map[string]time.Duration{"A": 0, "B": 0, "C": 20 * time.Millisecond, "D": 0} {
+		if a.Slack[name] != want {
+			t.Errorf("slack of %s: expected %v, got %v", name, want, a.Slack[name])
+		}
+	}
+	if a.Wall != 50*time.Millisecond || a.Work != 60*time.Millisecond || a.Workers != 2 {
+		t.Errorf("unexpected totals: wall %v, work %v, workers %d", a.Wall, a.Work, a.Workers)
+	}
+	if a.Efficiency != 0.6 {
+		t.Errorf("expected efficiency 0.6, got %v", a.Efficiency)
+	}
+
+	var buf bytes.Buffer
+	if err := a.Visualizer().Visualize(tf, &buf); err != nil {
+		t.Fatal(err)
+	}
+	dot := buf.String()
+	for _, line := range strings.Split(dot, "\n") {
+		critical := strings.Contains(line, "penwidth")
+		switch {
+		case strings.HasPrefix(strings.TrimSpace(line), `"G/C"`), strings.Contains(line, `-> "G/C"`), strings.HasPrefix(strings.TrimSpace(line), `"G/C" ->`):
+			if critical {
+				t.Errorf("C is not critical: %s", line)
+			}
+		case strings.Contains(line, `"G/A" -> "G/B"`), strings.Contains(line, `"G/B" -> "G/D"`), strings.HasPrefix(strings.TrimSpace(line), `"G/D" [`):
+			if !critical {
+				t.Errorf("expected highlight: %s", line)
+			}
+		}
+	}
+}
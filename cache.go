@@ -0,0 +1,170 @@
+package gotaskflow
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// TaskCache stores the outputs of successful task runs by cache key, see Task.Cache.
+// Implementations must be safe for concurrent use.
+type TaskCache interface {
+	// Get returns the output stored under key. ok is false if there is none.
+	Get(key string) (output []byte, ok bool, err error)
+	// Put stores output under key, replacing any previous one.
+	Put(key string, output []byte) error
+}
+
+// Cache makes the task skip running when the executor has a TaskCache, set by WithCache, holding the
+// output of a previous successful run under the same key. key derives the key from everything the
+// task depends on, such as its inputs, file digests and configuration; the output is captured and
+// restored by the functions set with Snapshot, a task without them is cached without output.
+// Only static tasks are cached.
+func (t *Task) Cache(key func() (string, error)) *Task {
+	if t.node.Typ != nodeStatic {
+		panic(fmt.Sprintf("task %q: only static tasks can be cached", t.Name()))
+	}
+	t.node.cacheKey = key
+	return t
+}
+
+// MemoryCache is a TaskCache living as long as the process.
+type MemoryCache struct {
+	outputs map[string][]byte
+	mu      *sync.Mutex
+}
+
+// NewMemoryCache returns an empty MemoryCache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		outputs: make(map[string][]byte),
+		mu:      &sync.Mutex{},
+	}
+}
+
+// Get implements TaskCache.
+func (c *MemoryCache) Get(key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out, ok := c.outputs[key]
+	return out, ok, nil
+}
+
+// Put implements TaskCache.
+func (c *MemoryCache) Put(key string, output []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.outputs[key] = output
+	return nil
+}
+
+// FileCache is a TaskCache keeping one file per key under a directory, so that it outlives the process.
+type FileCache struct {
+	dir string
+}
+
+// NewFileCache returns a FileCache rooted at dir, creating dir if needed.
+func NewFileCache(dir string) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create cache dir -> %w", err)
+	}
+	return &FileCache{dir: dir}, nil
+}
+
+func (c *FileCache) path(key string) string {
+	return filepath.Join(c.dir, key+".out")
+}
+
+// Get implements TaskCache.
+func (c *FileCache) Get(key string) ([]byte, bool, error) {
+	out, err := os.ReadFile(c.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("read cache -> %w", err)
+	}
+	return out, true, nil
+}
+
+// Put implements TaskCache. The output is written to a temporary file first, so that a crash
+// never leaves a partial output behind.
+func (c *FileCache) Put(key string, output []byte) error {
+	f, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create cache file -> %w", err)
+	}
+	if _, err := f.Write(output); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return fmt.Errorf("write cache -> %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("write cache -> %w", err)
+	}
+	if err := os.Rename(f.Name(), c.path(key)); err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("commit cache -> %w", err)
+	}
+	return nil
+}
+
+// cacheKey returns the key node is cached under: a digest of its path and of the key it declares,
+// so that tasks declaring the same key do not share outputs.
+func cacheKey(node *innerNode) (string, error) {
+	k, err := node.cacheKey()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(node.path() + "\x00" + k))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// cached calls run unless the cache holds the output of a previous successful run of node,
+// restoring it instead. A successful run stores the output. It reports whether run was skipped.
+// Cache failures are logged and fall back to running the task.
+func (e *innerExecutorImpl) cached(node *innerNode, run func()) bool {
+	if e.cache == nil || node.cacheKey == nil {
+		run()
+		return false
+	}
+	key, err := cacheKey(node)
+	if err != nil {
+		log.Printf("[go-taskflow] task %q: cache key failed, running it: %v", node.path(), err)
+		run()
+		return false
+	}
+
+	out, ok, err := e.cache.Get(key)
+	if err != nil {
+		log.Printf("[go-taskflow] task %q: cache lookup failed, running it: %v", node.path(), err)
+	} else if ok {
+		if node.restore == nil || out == nil {
+			return true
+		}
+		err := node.restore(out)
+		if err == nil {
+			return true
+		}
+		log.Printf("[go-taskflow] task %q: restore cached output failed, running it: %v", node.path(), err)
+	}
+
+	run()
+	out = nil
+	if node.snapshot != nil {
+		if out, err = node.snapshot(); err != nil {
+			log.Printf("[go-taskflow] task %q: snapshot output failed, not caching it: %v", node.path(), err)
+			return false
+		}
+	}
+	if err := e.cache.Put(key, out); err != nil {
+		log.Printf("[go-taskflow] task %q: cache store failed: %v", node.path(), err)
+	}
+	return false
+}
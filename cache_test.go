@@ -0,0 +1,156 @@
+package gotaskflow_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	gotaskflow "github.com/noneback/go-taskflow"
+)
+
+func TestCache(t *testing.T) {
+	var (
+		input    = "v1"
+		compiles int
+		links    int
+		binary   string
+	)
+	build := func() *gotaskflow.TaskFlow {
+		tf := gotaskflow.NewTaskFlow("build")
+		compile := tf.NewTask("compile", func() {
+			compiles++
+			binary = "bin(" + input + ")"
+		}).Snapshot(func() ([]byte, error) {
+			return []byte(binary), nil
+		}, func(out []byte) error {
+			binary = string(out)
+			return nil
+		}).Cache(func() (string, error) { return input, nil })
+		link := tf.NewTask("link", func() { links++ })
+		compile.Precede(link)
+		return tf
+	}
+
+	cache := gotaskflow.NewMemoryCache()
+	executor := gotaskflow.NewExecutor(2, gotaskflow.WithCache(cache))
+	executor.Run(build()).Wait()
+	binary = ""
+	executor.Run(build()).Wait()
+	if compiles != 1 || links != 2 {
+		t.Errorf("compile ran %d times and link %d, want 1 and 2", compiles, links)
+	}
+	if binary != "bin(v1)" {
+		t.Errorf("cached output %q not restored", binary)
+	}
+
+	input = "v2"
+	executor.Run(build()).Wait()
+	if compiles != 2 || binary != "bin(v2)" {
+		t.Errorf("changed key did not rerun compile: %d runs, output %q", compiles, binary)
+	}
+}
+
+func TestCacheFallback(t *testing.T) {
+	runs := 0
+	tf := gotaskflow.NewTaskFlow("G")
+	tf.NewTask("A", func() { runs++ }).Cache(func() (string, error) {
+		return "", errors.New("no digest")
+	})
+
+	executor := gotaskflow.NewExecutor(2, gotaskflow.WithCache(gotaskflow.NewMemoryCache()))
+	executor.Run(tf).Wait()
+	executor.Run(tf).Wait()
+	if runs != 2 {
+		t.Errorf("task with failing key ran %d times, want 2", runs)
+	}
+}
+
+func TestCacheStaticOnly(t *testing.T) {
+	tf := gotaskflow.NewTaskFlow("G")
+	cond := tf.NewCondition("C", func() uint { return 0 })
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic caching a condition")
+		}
+	}()
+	cond.Cache(func() (string, error) { return "", nil })
+}
+
+func TestCacheObserved(t *testing.T) {
+	tf := gotaskflow.NewTaskFlow("G")
+	A := tf.NewTask("A", func() {}).Cache(func() (string, error) { return "k", nil })
+	B := tf.NewTask("B", func() {})
+	A.Precede(B)
+
+	cache := gotaskflow.NewMemoryCache()
+	gotaskflow.NewExecutor(2, gotaskflow.WithCache(cache)).Run(tf).Wait()
+	executor := gotaskflow.NewExecutor(2, gotaskflow.WithCache(cache), gotaskflow.WithTracer(), gotaskflow.WithProfiler())
+	executor.Run(tf).Wait()
+
+	var trace bytes.Buffer
+	if err := executor.Trace(&trace); err != nil {
+		t.Fatal(err)
+	}
+	var events []struct {
+		Name string            `json:"name"`
+		Ph   string            `json:"ph"`
+		Args map[string]string `json:"args"`
+	}
+	if err := json.Unmarshal(trace.Bytes(), &events); err != nil {
+		t.Fatal(err)
+	}
+	outcomes := make(map[string]string)
+	for _, ev := range events {
+		if ev.Ph == "X" {
+			outcomes[ev.Name] = ev.Args["outcome"]
+		}
+	}
+	if outcomes["A"] != "cached" || outcomes["B"] != "" {
+		t.Errorf("unexpected outcomes in trace: %v", outcomes)
+	}
+
+	var profile bytes.Buffer
+	if err := executor.Profile(&profile); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(profile.String(), "static,A (cached),") {
+		t.Errorf("cached task not marked in profile:\n%s", profile.String())
+	}
+
+	viz, err := gotaskflow.NewExecutionVisualizer(bytes.NewReader(trace.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dot bytes.Buffer
+	if err := tf.DumpWith(viz, &dot); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(dot.String(), `fillcolor="#d9c8f0"`) {
+		t.Errorf("cached task not colored in annotated graph:\n%s", dot.String())
+	}
+}
+
+func TestFileCache(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := gotaskflow.NewFileCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := cache.Get("missing"); ok || err != nil {
+		t.Errorf("Get of missing key = %v, %v", ok, err)
+	}
+	if err := cache.Put("k", []byte("out")); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := gotaskflow.NewFileCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, ok, err := reopened.Get("k")
+	if !ok || err != nil || string(out) != "out" {
+		t.Errorf("Get = %q, %v, %v, want \"out\"", out, ok, err)
+	}
+}
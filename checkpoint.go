@@ -0,0 +1,277 @@
+package gotaskflow
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// CheckpointStore persists task completion records of a run, so that an interrupted run can be resumed.
+// Implementations must be safe for concurrent use.
+type CheckpointStore interface {
+	// Load returns all records saved under runID in saving order. An unknown runID yields no records.
+	Load(runID string) ([]CheckpointRecord, error)
+	// Save appends rec to the records of runID.
+	Save(runID string, rec CheckpointRecord) error
+}
+
+// CheckpointRecord marks one completed execution of a task within a run.
+type CheckpointRecord struct {
+	Task   string `json:"task"`             // position of the task in the flow, e.g. "2:sub/0:fetch"
+	Seq    int    `json:"seq"`              // n-th completion of the task in the run, counted from 0
+	Choice uint   `json:"choice,omitempty"` // branch taken, for condition tasks
+	Output []byte `json:"output,omitempty"` // output captured by Task.Snapshot
+}
+
+// FileCheckpointStore is a CheckpointStore keeping one JSON-lines file per run under a directory.
+type FileCheckpointStore struct {
+	dir string
+	mu  *sync.Mutex
+}
+
+// NewFileCheckpointStore returns a FileCheckpointStore rooted at dir, creating dir if needed.
+func NewFileCheckpointStore(dir string) (*FileCheckpointStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create checkpoint dir -> %w", err)
+	}
+	return &FileCheckpointStore{
+		dir: dir,
+		mu:  &sync.Mutex{},
+	}, nil
+}
+
+func (s *FileCheckpointStore) path(runID string) string {
+	return filepath.Join(s.dir, url.PathEscape(runID)+".ckpt")
+}
+
+// Load reads all records of runID. A partially written last line, left by a crash, is ignored and
+// truncated away, so that the records saved next start on a line of their own.
+func (s *FileCheckpointStore) Load(runID string) ([]CheckpointRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path(runID), os.O_RDWR, 0)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open checkpoint -> %w", err)
+	}
+	defer f.Close()
+
+	var (
+		recs    []CheckpointRecord
+		pending error
+		end     int64 // end of the last record read
+	)
+	r := bufio.NewReader(f)
+	for line := 1; ; line++ {
+		data, err := r.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("read checkpoint -> %w", err)
+		}
+		if len(data) == 0 {
+			break
+		}
+		if pending != nil {
+			return nil, pending
+		}
+		var rec CheckpointRecord
+		if data[len(data)-1] != '\n' {
+			pending = fmt.Errorf("checkpoint line %d is not terminated", line)
+		} else if err := json.Unmarshal(data, &rec); err != nil {
+			pending = fmt.Errorf("decode checkpoint line %d -> %w", line, err)
+		} else {
+			recs = append(recs, rec)
+			end += int64(len(data))
+		}
+	}
+
+	if pending != nil {
+		if err := f.Truncate(end); err != nil {
+			return nil, fmt.Errorf("truncate checkpoint -> %w", err)
+		}
+	}
+	return recs, nil
+}
+
+// Save appends rec to the file of runID.
+func (s *FileCheckpointStore) Save(runID string, rec CheckpointRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("encode checkpoint -> %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path(runID), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open checkpoint -> %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("write checkpoint -> %w", err)
+	}
+	return f.Close()
+}
+
+// Remove deletes all records of runID.
+func (s *FileCheckpointStore) Remove(runID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.path(runID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove checkpoint -> %w", err)
+	}
+	return nil
+}
+
+type checkpointKey struct {
+	task string
+	seq  int
+}
+
+// checkpointRun replays and records task completions of one resumable run.
+type checkpointRun struct {
+	id    string
+	store CheckpointStore
+	mu    *sync.Mutex
+	done  map[checkpointKey]CheckpointRecord
+	seq   map[string]int
+	keys  map[*innerNode]string
+}
+
+func newCheckpointRun(id string, store CheckpointStore, recs []CheckpointRecord) *checkpointRun {
+	c := &checkpointRun{
+		id:    id,
+		store: store,
+		mu:    &sync.Mutex{},
+		done:  make(map[checkpointKey]CheckpointRecord, len(recs)),
+		seq:   make(map[string]int),
+		keys:  make(map[*innerNode]string),
+	}
+	for _, rec := range recs {
+		c.done[checkpointKey{task: rec.Task, seq: rec.Seq}] = rec
+	}
+	return c
+}
+
+// key returns the position of n in the flow. Positions rather than names identify tasks,
+// since names are not required to be unique.
+func (c *checkpointRun) key(n *innerNode) string {
+	if k, ok := c.keys[n]; ok {
+		return k
+	}
+	prefix := ""
+	if n.g.parent != nil {
+		prefix = c.key(n.g.parent) + "/"
+	}
+	for i, m := range n.g.nodes {
+		c.keys[m] = prefix + strconv.Itoa(i) + ":" + m.name
+	}
+	return c.keys[n]
+}
+
+// forget drops the positions of the tasks of g, which a rebuild is about to discard.
+func (c *checkpointRun) forget(g *eGraph) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	g.walk(func(n *innerNode) { delete(c.keys, n) })
+}
+
+// step starts a new execution of n and returns its record. ok reports whether
+// a previous attempt of the run already completed this execution.
+func (c *checkpointRun) step(n *innerNode) (rec CheckpointRecord, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	k := c.key(n)
+	seq := c.seq[k]
+	c.seq[k] = seq + 1
+	if rec, ok := c.done[checkpointKey{task: k, seq: seq}]; ok {
+		return rec, true
+	}
+	return CheckpointRecord{Task: k, Seq: seq}, false
+}
+
+func (c *checkpointRun) commit(rec CheckpointRecord) {
+	if err := c.store.Save(c.id, rec); err != nil {
+		log.Printf("[go-taskflow] run %q: save checkpoint of %q failed: %v", c.id, rec.Task, err)
+	}
+}
+
+// runState holds the state shared by every graph of one TaskFlow execution.
+type runState struct {
+	info *RunInfo
+	ckpt *checkpointRun      // nil unless the run is resumable
+	only map[*innerNode]bool // top-level tasks run by RunTargets or RunFrom, nil for every task
+	from *innerNode          // top-level task RunFrom runs from, nil otherwise
+
+	mu    *sync.Mutex
+	iters map[string]int // executions started by task path
+}
+
+func newRunState(tf *TaskFlow, id string) *runState {
+	return &runState{
+		info:  &RunInfo{Flow: tf.Name(), ID: id},
+		mu:    &sync.Mutex{},
+		iters: make(map[string]int),
+	}
+}
+
+// iteration starts an execution of the task at path and returns how many started before it in the run.
+func (r *runState) iteration(path string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := r.iters[path]
+	r.iters[path] = n + 1
+	return n
+}
+
+// forget releases what the run keeps about the tasks of g, before a rebuild discards them.
+func (r *runState) forget(g *eGraph) {
+	if r.ckpt != nil {
+		r.ckpt.forget(g)
+	}
+}
+
+// replay starts a new execution of node. It reports whether the execution was
+// completed by a previous attempt of the run, restoring the task output if so.
+func (r *runState) replay(node *innerNode) (CheckpointRecord, bool) {
+	if r.ckpt == nil {
+		return CheckpointRecord{}, false
+	}
+	rec, ok := r.ckpt.step(node)
+	if ok && node.restore != nil && rec.Output != nil {
+		if err := node.restore(rec.Output); err != nil {
+			panic(fmt.Sprintf("restore checkpoint output of %q -> %v", node.name, err))
+		}
+	}
+	return rec, ok
+}
+
+// record persists a completed execution of node started by replay. If the task output
+// cannot be captured, nothing is recorded and a resumed run executes the task again.
+func (r *runState) record(node *innerNode, rec CheckpointRecord) {
+	if r.ckpt == nil {
+		return
+	}
+	if node.snapshot != nil {
+		out, err := node.snapshot()
+		if err != nil {
+			log.Printf("[go-taskflow] run %q: snapshot output of %q failed: %v", r.ckpt.id, node.name, err)
+			return
+		}
+		rec.Output = out
+	}
+	r.ckpt.commit(rec)
+}
@@ -0,0 +1,46 @@
+package gotaskflow
+
+import "testing"
+
+func TestCheckpointForgetsRebuiltSubflows(t *testing.T) {
+	store, err := NewFileCheckpointStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var i int
+	tf := NewTaskFlow("G")
+	init := tf.NewTask("init", func() { i = 0 })
+	sub := tf.NewSubflow("S", func(sf *Subflow) {
+		sf.NewTask("a", func() {}).Precede(sf.NewTask("b", func() {}))
+	}).Rebuild()
+	next := tf.NewCondition("next", func() uint {
+		if i++; i < 10 {
+			return 0
+		}
+		return 1
+	})
+	init.Precede(sub)
+	sub.Precede(next)
+	next.Precede(sub, tf.NewTask("done", func() {}))
+
+	for name, run := range map[string]func(rs *runState){
+		"executor": func(rs *runState) {
+			e := NewExecutor(4, WithCheckpoint(store)).(*innerExecutorImpl)
+			e.run(tf, rs)
+			e.Wait()
+		},
+		"simulator": func(rs *runState) { NewSimulator(1, WithCheckpoint(store)).run(tf, rs) },
+	} {
+		rs := newRunState(tf, name)
+		rs.ckpt = newCheckpointRun(name, store, nil)
+		run(rs)
+		if i != 10 {
+			t.Fatalf("%s: expected 10 iterations, got %d", name, i)
+		}
+		// the 4 top-level tasks and the tasks of the last build of S
+		if got := len(rs.ckpt.keys); got != 6 {
+			t.Errorf("%s: expected the keys of 6 tasks, got %d", name, got)
+		}
+		tf.Reset()
+	}
+}
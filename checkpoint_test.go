@@ -0,0 +1,237 @@
+package gotaskflow_test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	gotaskflow "github.com/noneback/go-taskflow"
+	"github.com/noneback/go-taskflow/utils"
+)
+
+func newTestStore(t *testing.T) *gotaskflow.FileCheckpointStore {
+	store, err := gotaskflow.NewFileCheckpointStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	return store
+}
+
+func TestResumeSkipsFinishedTasks(t *testing.T) {
+	store := newTestStore(t)
+	var a, b, c, sub atomic.Int32
+	crash := true
+
+	build := func() *gotaskflow.TaskFlow {
+		tf := gotaskflow.NewTaskFlow("G")
+		A := tf.NewTask("A", func() { a.Add(1) })
+		S := tf.NewSubflow("S", func(sf *gotaskflow.Subflow) {
+			sf.NewTask("S1", func() { sub.Add(1) })
+		})
+		B := tf.NewTask("B", func() { b.Add(1) })
+		C := tf.NewTask("C", func() {
+			if crash {
+				panic("crash")
+			}
+			c.Add(1)
+		})
+		A.Precede(S)
+		S.Precede(B)
+		B.Precede(C)
+		return tf
+	}
+
+	executor := gotaskflow.NewExecutor(10, gotaskflow.WithCheckpoint(store))
+	executor.Resume(build(), "run-1").Wait()
+	if c.Load() != 0 {
+		t.Fatalf("C should have crashed, ran %d times", c.Load())
+	}
+
+	crash = false
+	executor = gotaskflow.NewExecutor(10, gotaskflow.WithCheckpoint(store))
+	executor.Resume(build(), "run-1").Wait()
+
+	if a.Load() != 1 || b.Load() != 1 || sub.Load() != 1 {
+		t.Errorf("finished tasks rerun: A=%d B=%d S1=%d", a.Load(), b.Load(), sub.Load())
+	}
+	if c.Load() != 1 {
+		t.Errorf("expected C to run once after resume, got %d", c.Load())
+	}
+}
+
+func TestResumeLoop(t *testing.T) {
+	store := newTestStore(t)
+	var (
+		i      int
+		bodies int
+		crash  = true
+	)
+
+	build := func() *gotaskflow.TaskFlow {
+		tf := gotaskflow.NewTaskFlow("loop")
+		init := tf.NewTask("init", func() { i = 0 })
+		cond := tf.NewCondition("i < 5", func() uint {
+			if i < 5 {
+				return 0
+			}
+			return 1
+		})
+		body := tf.NewTask("body", func() {
+			if crash && i == 3 {
+				panic("crash")
+			}
+			bodies++
+			i++
+		}).Snapshot(func() ([]byte, error) {
+			return []byte(strconv.Itoa(i)), nil
+		}, func(b []byte) error {
+			v, err := strconv.Atoi(string(b))
+			i = v
+			return err
+		})
+		back := tf.NewCondition("back", func() uint { return 0 })
+		done := tf.NewTask("done", func() {})
+
+		init.Precede(cond)
+		cond.Precede(body, done)
+		body.Precede(back)
+		back.Precede(cond)
+		return tf
+	}
+
+	gotaskflow.NewExecutor(4, gotaskflow.WithCheckpoint(store)).Resume(build(), "loop-1").Wait()
+	if bodies != 3 {
+		t.Fatalf("expected 3 bodies before crash, got %d", bodies)
+	}
+
+	crash = false
+	i = -100
+	gotaskflow.NewExecutor(4, gotaskflow.WithCheckpoint(store)).Resume(build(), "loop-1").Wait()
+
+	if i != 5 {
+		t.Errorf("expected i = 5 after resume, got %d", i)
+	}
+	if bodies != 5 {
+		t.Errorf("expected 2 more bodies after resume, got %d in total", bodies)
+	}
+}
+
+func TestResumeAfterTornWrite(t *testing.T) {
+	dir := t.TempDir()
+	store, err := gotaskflow.NewFileCheckpointStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		runs  [3]atomic.Int32
+		crash = "B"
+	)
+	build := func() *gotaskflow.TaskFlow {
+		tf := gotaskflow.NewTaskFlow("G")
+		var prev *gotaskflow.Task
+		for i, name := range []string{"A", "B", "C"} {
+			i, name := i, name
+			task := tf.NewTask(name, func() {
+				if crash == name {
+					panic("crash")
+				}
+				runs[i].Add(1)
+			})
+			if prev != nil {
+				prev.Precede(task)
+			}
+			prev = task
+		}
+		return tf
+	}
+	tear := func() {
+		f, err := os.OpenFile(filepath.Join(dir, "run-1.ckpt"), os.O_APPEND|os.O_WRONLY, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteString(`{"task":"1:B","se`)
+		f.Close()
+	}
+
+	gotaskflow.NewExecutor(4, gotaskflow.WithCheckpoint(store)).Resume(build(), "run-1").Wait()
+	tear()
+	crash = "C"
+	gotaskflow.NewExecutor(4, gotaskflow.WithCheckpoint(store)).Resume(build(), "run-1").Wait()
+	tear()
+	crash = ""
+	gotaskflow.NewExecutor(4, gotaskflow.WithCheckpoint(store)).Resume(build(), "run-1").Wait()
+
+	for i, want := range []int32{1, 1, 1} {
+		if got := runs[i].Load(); got != want {
+			t.Errorf("task %d ran %d times, want %d", i, got, want)
+		}
+	}
+	recs, err := store.Load("run-1")
+	if err != nil || len(recs) != 3 {
+		t.Errorf("expected 3 records, got %v, %v", recs, err)
+	}
+}
+
+func TestResumeWithoutCheckpointStore(t *testing.T) {
+	executor := gotaskflow.NewExecutor(4)
+	tf := gotaskflow.NewTaskFlow("G")
+	tf.NewTask("A", func() {})
+
+	utils.AssertPanics(t, "no store", func() {
+		executor.Resume(tf, "run")
+	})
+}
+
+func TestFileCheckpointStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := gotaskflow.NewFileCheckpointStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recs, err := store.Load("unknown")
+	if err != nil || len(recs) != 0 {
+		t.Fatalf("expected no records for unknown run, got %v, %v", recs, err)
+	}
+
+	want := []gotaskflow.CheckpointRecord{
+		{Task: "0:A", Seq: 0},
+		{Task: "1:cond", Seq: 0, Choice: 1},
+		{Task: "2:B", Seq: 0, Output: []byte("out")},
+	}
+	for _, rec := range want {
+		if err := store.Save("run/1", rec); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+	}
+
+	// simulate a crash in the middle of writing a record
+	f, err := os.OpenFile(filepath.Join(dir, "run%2F1.ckpt"), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"task":"3:C","se`)
+	f.Close()
+
+	got, err := store.Load("run/1")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d records, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i].Task != want[i].Task || got[i].Choice != want[i].Choice || string(got[i].Output) != string(want[i].Output) {
+			t.Errorf("record %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+
+	if err := store.Remove("run/1"); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if got, _ := store.Load("run/1"); len(got) != 0 {
+		t.Errorf("expected no records after remove, got %d", len(got))
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	gotaskflow "github.com/noneback/go-taskflow"
+)
+
+// planNode is a task of a flattened spec: subflows precede the entries of their tasks, whose exits
+// precede the successors of the subflow.
+type planNode struct {
+	path  string
+	cost  time.Duration
+	succs []*planNode
+}
+
+type plan struct {
+	nodes []*planNode
+	costs map[string]time.Duration
+	def   time.Duration
+}
+
+func (p *plan) node(path string) *planNode {
+	cost, ok := p.costs[path]
+	if !ok {
+		cost = p.def
+	}
+	n := &planNode{path: path, cost: cost}
+	p.nodes = append(p.nodes, n)
+	return n
+}
+
+// flatten adds tasks to p and returns the nodes starting and ending them.
+func (p *plan) flatten(tasks []gotaskflow.TaskSpec, prefix string) (entries, exits []*planNode) {
+	nodes := make(map[string]*planNode, len(tasks))
+	outs := make(map[string][]*planNode, len(tasks)) // the nodes successors of each task wait for
+	for _, t := range tasks {
+		n := p.node(prefix + t.Name)
+		nodes[t.Name] = n
+		outs[t.Name] = []*planNode{n}
+		if len(t.Tasks) > 0 {
+			in, out := p.flatten(t.Tasks, prefix+t.Name+"/")
+			n.succs = append(n.succs, in...)
+			if len(out) > 0 {
+				outs[t.Name] = out
+			}
+		}
+	}
+
+	hasPred := make(map[string]bool, len(tasks))
+	for _, t := range tasks {
+		for _, succ := range t.Precede {
+			hasPred[succ] = true
+			for _, o := range outs[t.Name] {
+				o.succs = append(o.succs, nodes[succ])
+			}
+		}
+	}
+	for _, t := range tasks {
+		if !hasPred[t.Name] {
+			entries = append(entries, nodes[t.Name])
+		}
+		if len(t.Precede) == 0 {
+			exits = append(exits, outs[t.Name]...)
+		}
+	}
+	return entries, exits
+}
+
+// order returns the nodes of p in topological order, ignoring the edges closing cycles,
+// which only condition tasks may form in a valid flow.
+func (p *plan) order() []*planNode {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[*planNode]int, len(p.nodes))
+	back := make(map[[2]*planNode]bool)
+	var post []*planNode
+	var visit func(n *planNode)
+	visit = func(n *planNode) {
+		state[n] = visiting
+		for _, s := range n.succs {
+			switch state[s] {
+			case unvisited:
+				visit(s)
+			case visiting:
+				back[[2]*planNode{n, s}] = true
+			}
+		}
+		state[n] = visited
+		post = append(post, n)
+	}
+	for _, n := range p.nodes {
+		if state[n] == unvisited {
+			visit(n)
+		}
+	}
+
+	for _, n := range p.nodes {
+		kept := n.succs[:0]
+		for _, s := range n.succs {
+			if !back[[2]*planNode{n, s}] {
+				kept = append(kept, s)
+			}
+		}
+		n.succs = kept
+	}
+	for i, j := 0, len(post)-1; i < j; i, j = i+1, j-1 {
+		post[i], post[j] = post[j], post[i]
+	}
+	return post
+}
+
+func analyze(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("analyze", stdout)
+	costsPath := fs.String("costs", "", "JSON object of estimated costs by task path, e.g. {\"sub/task\": \"1.5s\"}")
+	def := fs.Duration("default-cost", time.Second, "estimated cost of tasks missing from -costs")
+	path, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	// build to report spec errors
+	spec, _, err := buildSpec(path, stdin)
+	if err != nil {
+		return err
+	}
+
+	p := &plan{costs: make(map[string]time.Duration), def: *def}
+	if *costsPath != "" {
+		raw, err := os.ReadFile(*costsPath)
+		if err != nil {
+			return err
+		}
+		var costs map[string]string
+		if err := json.Unmarshal(raw, &costs); err != nil {
+			return fmt.Errorf("decode costs -> %w", err)
+		}
+		for task, c := range costs {
+			d, err := time.ParseDuration(c)
+			if err != nil {
+				return fmt.Errorf("cost of %q -> %w", task, err)
+			}
+			p.costs[task] = d
+		}
+	}
+	p.flatten(spec.Tasks, "")
+	nodes := p.order()
+
+	level := make(map[*planNode]int, len(nodes))
+	finish := make(map[*planNode]time.Duration, len(nodes))
+	via := make(map[*planNode]*planNode, len(nodes))
+	for _, n := range nodes {
+		finish[n] += n.cost
+		for _, s := range n.succs {
+			level[s] = max(level[s], level[n]+1)
+			if finish[n] > finish[s] {
+				finish[s] = finish[n]
+				via[s] = n
+			}
+		}
+	}
+	depth, widths := 0, make(map[int]int)
+	var last *planNode
+	for _, n := range nodes {
+		depth = max(depth, level[n]+1)
+		widths[level[n]]++
+		if last == nil || finish[n] > finish[last] {
+			last = n
+		}
+	}
+	width := 0
+	for _, w := range widths {
+		width = max(width, w)
+	}
+
+	fmt.Fprintf(stdout, "%s: %d tasks, depth %d, width %d\n", spec.Name, len(nodes), depth, width)
+	if last == nil {
+		return nil
+	}
+	var steps []*planNode
+	for n := last; n != nil; n = via[n] {
+		steps = append([]*planNode{n}, steps...)
+	}
+	fmt.Fprintf(stdout, "critical path %v:\n", finish[last])
+	for _, n := range steps {
+		fmt.Fprintf(stdout, "  +%v %s\n", n.cost, n.path)
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+// Command taskflow lints, renders and analyzes declarative flow specs, and summarizes traces.
+//
+// Usage:
+//
+//	taskflow lint spec.json|spec.yaml
+//	taskflow render [-format dot|mermaid|json|graphml|svg] [-o file] spec.json|spec.yaml
+//	taskflow analyze [-costs costs.json] [-default-cost 1s] spec.json|spec.yaml
+//	taskflow trace [-workers n] [-top n] trace.json
+//
+// Specs are the FlowSpecs read by gotaskflow.ParseFlowSpec, "-" reads from stdin. They are JSON, or
+// YAML without anchors, tags, block scalars and multi-line scalars.
+// Task implementations are not needed: every registry entry a spec names is stubbed.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	gotaskflow "github.com/noneback/go-taskflow"
+)
+
+const usage = `usage: taskflow <command> [flags] <file>
+
+commands:
+  lint      validate a flow spec, exit status 1 if it has problems
+  render    write the graph of a flow spec in DOT, Mermaid, JSON, GraphML or SVG
+  analyze   report the width, depth and critical path of a flow spec from estimated costs
+  trace     summarize a Chrome trace written by Executor.Trace
+
+Specs are JSON, or YAML with block and flow collections, plain and quoted
+scalars on one line and comments; anchors, tags, block scalars and
+multi-line scalars are rejected. "-" reads from stdin.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the exit status.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	cmds := map[string]func(args []string, stdin io.Reader, stdout io.Writer) error{
+		"lint":    lint,
+		"render":  render,
+		"analyze": analyze,
+		"trace":   summarize,
+	}
+	cmd, ok := cmds[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n%s", args[0], usage)
+		return 2
+	}
+	err := cmd(args[1:], stdin, stdout)
+	var problems *problemsError
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.As(err, &problems):
+		fmt.Fprint(stdout, problems.Error())
+		return 1
+	case errors.Is(err, errUsage), errors.Is(err, errBadFlag):
+		fmt.Fprintf(stderr, "taskflow %s: %v\n", args[0], err)
+		return 2
+	default:
+		fmt.Fprintf(stderr, "taskflow %s: %v\n", args[0], err)
+		return 1
+	}
+}
+
+var (
+	errUsage   = errors.New("expected one file argument")
+	errBadFlag = errors.New("invalid flag")
+)
+
+// problemsError lists the problems found by lint, one per line.
+type problemsError struct {
+	problems []string
+}
+
+func (e *problemsError) Error() string {
+	return strings.Join(e.problems, "\n") + "\n"
+}
+
+func newFlagSet(name string, stdout io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stdout)
+	return fs
+}
+
+// parse parses the flags of args and returns the file argument.
+func parse(fs *flag.FlagSet, args []string) (string, error) {
+	if err := fs.Parse(args); err != nil {
+		return "", err
+	}
+	if fs.NArg() != 1 {
+		return "", errUsage
+	}
+	return fs.Arg(0), nil
+}
+
+func open(path string, stdin io.Reader) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(stdin), nil
+	}
+	return os.Open(path)
+}
+
+func readSpec(path string, stdin io.Reader) (*gotaskflow.FlowSpec, error) {
+	f, err := open(path, stdin)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return gotaskflow.ParseFlowSpec(f)
+}
+
+// stubRegistry registers a no-op for every registry entry spec names, with the kind of its first use.
+func stubRegistry(spec *gotaskflow.FlowSpec) *gotaskflow.Registry {
+	reg := gotaskflow.NewRegistry()
+	seen := make(map[string]bool)
+	var stub func(tasks []gotaskflow.TaskSpec)
+	stub = func(tasks []gotaskflow.TaskSpec) {
+		for _, t := range tasks {
+			fn := t.Func
+			if fn == "" {
+				fn = t.Name
+			}
+			stub(t.Tasks)
+			if seen[fn] {
+				continue
+			}
+			switch t.Kind {
+			case "", "static":
+				reg.Static(fn, func() {})
+			case "condition":
+				reg.Condition(fn, func() uint { return 0 })
+			case "subflow":
+				if len(t.Tasks) > 0 {
+					continue
+				}
+				reg.Subflow(fn, func(*gotaskflow.Subflow) {})
+			default:
+				continue
+			}
+			seen[fn] = true
+		}
+	}
+	stub(spec.Tasks)
+	return reg
+}
+
+// buildSpec reads the spec at path and builds its flow with stubbed tasks.
+func buildSpec(path string, stdin io.Reader) (*gotaskflow.FlowSpec, *gotaskflow.TaskFlow, error) {
+	spec, err := readSpec(path, stdin)
+	if err != nil {
+		return nil, nil, err
+	}
+	tf, err := stubRegistry(spec).Build(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+	return spec, tf, nil
+}
+
+func lint(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("lint", stdout)
+	path, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	_, tf, err := buildSpec(path, stdin)
+	var specErr *gotaskflow.SpecError
+	if errors.As(err, &specErr) {
+		return &problemsError{problems: specErr.Problems}
+	} else if err != nil {
+		return err
+	}
+
+	var graphErr *gotaskflow.GraphError
+	if err := tf.Validate(); errors.As(err, &graphErr) {
+		return &problemsError{problems: graphErr.Problems}
+	} else if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%s: ok\n", tf.Name())
+	return nil
+}
+
+func render(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("render", stdout)
+	format := fs.String("format", "dot", "output format: dot, mermaid, json, graphml or svg")
+	output := fs.String("o", "", "output file, stdout if empty")
+	path, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	visualizers := map[string]func() gotaskflow.Visualizer{
+		"dot":     gotaskflow.NewDOTVisualizer,
+		"svg":     gotaskflow.NewDOTVisualizer,
+		"mermaid": gotaskflow.NewMermaidVisualizer,
+		"json":    gotaskflow.NewJSONVisualizer,
+		"graphml": gotaskflow.NewGraphMLVisualizer,
+	}
+	newVisualizer, ok := visualizers[*format]
+	if !ok {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	_, tf, err := buildSpec(path, stdin)
+	if err != nil {
+		return err
+	}
+
+	write := func(w io.Writer) error {
+		if *format != "svg" {
+			return tf.DumpWith(newVisualizer(), w)
+		}
+
+		// svg is rendered from DOT by Graphviz
+		var dot strings.Builder
+		if err := tf.Dump(&dot); err != nil {
+			return err
+		}
+		cmd := exec.Command("dot", "-Tsvg")
+		cmd.Stdin = strings.NewReader(dot.String())
+		cmd.Stdout = w
+		var stderr strings.Builder
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("render svg with graphviz dot -> %w %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return nil
+	}
+	if *output == "" {
+		return write(stdout)
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gotaskflow "github.com/noneback/go-taskflow"
+)
+
+func runCmd(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestLint(t *testing.T) {
+	if code, out, _ := runCmd(t, "", "lint", "testdata/pipeline.json"); code != 0 || out != "pipeline: ok\n" {
+		t.Errorf("expected pipeline to pass, got %d %q", code, out)
+	}
+	if code, out, _ := runCmd(t, "", "lint", "testdata/pipeline.yaml"); code != 0 || out != "pipeline: ok\n" {
+		t.Errorf("expected YAML pipeline to pass, got %d %q", code, out)
+	}
+
+	code, out, _ := runCmd(t, "", "lint", "testdata/invalid.json")
+	if code != 1 || !strings.Contains(out, `task "C": precedes unknown task "D"`) || !strings.Contains(out, `task "E": unknown kind "loop"`) {
+		t.Errorf("expected spec problems, got %d %q", code, out)
+	}
+
+	code, out, _ = runCmd(t, "", "lint", "testdata/cycle.json")
+	if code != 1 || out != "cycle without a condition task: \"B\", \"C\"\n" {
+		t.Errorf("expected a cycle, got %d %q", code, out)
+	}
+
+	spec, err := os.ReadFile("testdata/cycle.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code, _, _ := runCmd(t, string(spec), "lint", "-"); code != 1 {
+		t.Errorf("expected the spec read from stdin to fail, got %d", code)
+	}
+}
+
+func TestRender(t *testing.T) {
+	for format, want := range map[string]string{
+		"dot":     `digraph "pipeline"`,
+		"mermaid": "flowchart LR",
+		"json":    `"name": "pipeline"`,
+		"graphml": "<graphml",
+	} {
+		code, out, errOut := runCmd(t, "", "render", "-format", format, "testdata/pipeline.json")
+		if code != 0 || !strings.Contains(out, want) || !strings.Contains(out, "enrich") {
+			t.Errorf("%s: expected %q, got %d %q %q", format, want, code, out, errOut)
+		}
+	}
+
+	output := filepath.Join(t.TempDir(), "pipeline.mmd")
+	if code, _, _ := runCmd(t, "", "render", "-format", "mermaid", "-o", output, "testdata/pipeline.json"); code != 0 {
+		t.Fatalf("expected to render into %s", output)
+	}
+	if raw, err := os.ReadFile(output); err != nil || !strings.Contains(string(raw), "flowchart LR") {
+		t.Errorf("unexpected output file %q, %v", raw, err)
+	}
+	if _, err := os.Stat("/dev/full"); err == nil {
+		if code, _, _ := runCmd(t, "", "render", "-o", "/dev/full", "testdata/pipeline.json"); code != 1 {
+			t.Errorf("expected a failed write to be reported, got %d", code)
+		}
+	}
+
+	if code, _, errOut := runCmd(t, "", "render", "-format", "png", "testdata/pipeline.json"); code != 1 || !strings.Contains(errOut, `unknown format "png"`) {
+		t.Errorf("expected an unknown format, got %d %q", code, errOut)
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	code, out, errOut := runCmd(t, "", "analyze", "-costs", "testdata/costs.json", "testdata/pipeline.json")
+	if code != 0 {
+		t.Fatalf("analyze failed: %s", errOut)
+	}
+	for _, want := range []string{
+		"pipeline: 8 tasks, depth 7, width 2",
+		"critical path 8.5s:",
+		"  +3s transform/enrich\n  +500ms load\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestTrace(t *testing.T) {
+	sim := gotaskflow.NewSimulator(1, gotaskflow.WithTracer())
+	tf := gotaskflow.NewTaskFlow("G")
+	A := tf.NewTask("A", func() { sim.Clock().Sleep(2 * time.Millisecond) })
+	S := tf.NewSubflow("S", func(sf *gotaskflow.Subflow) {
+		sf.NewTask("x", func() { sim.Clock().Sleep(5 * time.Millisecond) })
+	})
+	A.Precede(S)
+	sim.Run(tf)
+
+	trace := filepath.Join(t.TempDir(), "trace.json")
+	f, err := os.Create(trace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.Trace(f); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	code, out, errOut := runCmd(t, "", "trace", "-top", "2", trace)
+	if code != 0 {
+		t.Fatalf("trace failed: %s", errOut)
+	}
+	for _, want := range []string{"3 executions of 3 tasks", "wall 7ms", "S/x", "tasks by total time:\n  5ms G/S/x ×1\n  2ms G/A ×1\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+
+	if code, _, errOut := runCmd(t, "", "trace", "-top", "-1", trace); code != 2 || !strings.Contains(errOut, "-top must not be negative") {
+		t.Errorf("expected a usage error for a negative -top, got %d %q", code, errOut)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	if code, _, errOut := runCmd(t, ""); code != 2 || !strings.Contains(errOut, "usage") {
+		t.Errorf("expected usage, got %d %q", code, errOut)
+	}
+	if code, _, _ := runCmd(t, "", "deploy"); code != 2 {
+		t.Errorf("expected an unknown command, got %d", code)
+	}
+	if code, _, _ := runCmd(t, "", "lint"); code != 2 {
+		t.Errorf("expected a missing file, got %d", code)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	gotaskflow "github.com/noneback/go-taskflow"
+)
+
+// traceEvent holds the fields of the Chrome trace events written by Executor.Trace used here.
+type traceEvent struct {
+	Name string            `json:"name"`
+	Ph   string            `json:"ph"`
+	Dur  int64             `json:"dur"`
+	Args map[string]string `json:"args"`
+}
+
+type taskSummary struct {
+	name   string
+	runs   int
+	total  time.Duration
+	failed int
+	cached int
+}
+
+func summarize(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := newFlagSet("trace", stdout)
+	workers := fs.Int("workers", 0, "concurrency of the executor, the number of worker rows of the trace if 0")
+	top := fs.Int("top", 10, "number of tasks listed by total time")
+	path, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if *top < 0 {
+		return fmt.Errorf("%w: -top must not be negative", errBadFlag)
+	}
+	f, err := open(path, stdin)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	raw, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+
+	var events []traceEvent
+	if err := json.Unmarshal(raw, &events); err != nil {
+		return fmt.Errorf("decode trace -> %w", err)
+	}
+	tasks := make(map[string]*taskSummary)
+	executions := 0
+	for _, ev := range events {
+		if ev.Ph != "X" {
+			continue
+		}
+		executions++
+		name := ev.Args["path"]
+		if name == "" {
+			name = ev.Name
+			if p := ev.Args["parent"]; p != "" {
+				name = p + "/" + name
+			}
+		}
+		s, ok := tasks[name]
+		if !ok {
+			s = &taskSummary{name: name}
+			tasks[name] = s
+		}
+		s.runs++
+		s.total += time.Duration(ev.Dur) * time.Microsecond
+		switch ev.Args["outcome"] {
+		case "", gotaskflow.TaskRestored.String():
+		case gotaskflow.TaskCached.String():
+			s.cached++
+		default:
+			s.failed++
+		}
+	}
+
+	analysis, err := gotaskflow.Analyze(bytes.NewReader(raw), nil, *workers)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%d executions of %d tasks\n", executions, len(tasks))
+	fmt.Fprint(stdout, analysis.String())
+
+	byTotal := make([]*taskSummary, 0, len(tasks))
+	for _, s := range tasks {
+		byTotal = append(byTotal, s)
+	}
+	sort.Slice(byTotal, func(i, j int) bool {
+		if byTotal[i].total != byTotal[j].total {
+			return byTotal[i].total > byTotal[j].total
+		}
+		return byTotal[i].name < byTotal[j].name
+	})
+	if len(byTotal) > *top {
+		byTotal = byTotal[:*top]
+	}
+	fmt.Fprintln(stdout, "tasks by total time:")
+	for _, s := range byTotal {
+		line := fmt.Sprintf("  %v %s ×%d", s.total, s.name, s.runs)
+		if s.cached > 0 {
+			line += fmt.Sprintf(", %d cached", s.cached)
+		}
+		if s.failed > 0 {
+			line += fmt.Sprintf(", %d failed or canceled", s.failed)
+		}
+		fmt.Fprintln(stdout, line)
+	}
+	return nil
+}
@@ -0,0 +1,358 @@
+package gotaskflow
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DryRunPlan holds the assumptions of Executor.DryRun. Tasks are named by their path below the flow,
+// e.g. "sub/task".
+type DryRunPlan struct {
+	Costs   map[string]time.Duration // estimated cost of tasks
+	Default time.Duration            // cost of the tasks missing from Costs, subflows cost nothing but their tasks
+	// Branches taken by the successive executions of each condition. Once exhausted the last one repeats,
+	// conditions missing take branch 0.
+	Branches map[string][]uint
+}
+
+// Schedule is the run of a TaskFlow predicted by Executor.DryRun.
+type Schedule struct {
+	Flow     string
+	Workers  int
+	Makespan time.Duration  // from the start of the run to the end of its last task
+	Slots    []ScheduleSlot // task executions in the order workers picked them
+	Stalled  []string       // tasks never picked since subflows held every worker, the run would deadlock
+}
+
+// ScheduleSlot is one predicted task execution, times are since the run started.
+type ScheduleSlot struct {
+	Path   string
+	Worker int           // from 1
+	Queued time.Duration // scheduled once its predecessors were done
+	Picked time.Duration // taken by the worker, which holds it until End waiting for semaphores first
+	Start  time.Duration
+	End    time.Duration // for subflows once their tasks are done
+}
+
+// dryRunMaxExecutions bounds dry runs whose Branches never leave a loop.
+const dryRunMaxExecutions = 1 << 20
+
+// DryRun predicts the schedule of tf on this executor without running its tasks: tasks cost their
+// estimate in plan, conditions take the branches plan gives them, and scheduling follows the executor,
+// with its priorities, join counters, semaphores and concurrency. Subflows not instantiated yet call
+// their function to declare their tasks, which is the only user code DryRun runs; they are discarded
+// afterwards, so tf is left as it was. DryRun panics if tf is frozen: dry run it before running it, or
+// after Reset.
+func (e *innerExecutorImpl) DryRun(tf *TaskFlow, plan DryRunPlan) *Schedule {
+	if tf.frozen {
+		panic(fmt.Sprintf("taskflow %q is frozen, cannot dry run it, Reset it first", tf.Name()))
+	}
+	d := &dryRun{
+		plan:   plan,
+		sched:  &Schedule{Flow: tf.Name(), Workers: int(e.concurrency)},
+		busy:   make([]bool, e.concurrency),
+		tokens: make(map[*Semaphore]int),
+		queues: make(map[*Semaphore][]*dryTask),
+		joins:  make(map[*eGraph]func()),
+		conds:  make(map[*innerNode]int),
+	}
+	tf.graph.run = newRunState(tf, "")
+	d.scheduleGraph(tf.graph, func() {})
+	for d.step() {
+	}
+	for _, q := range d.ready {
+		d.sched.Stalled = append(d.sched.Stalled, q.node.localPath())
+	}
+
+	for _, g := range d.built {
+		g.clear()
+	}
+	rewind(tf.graph)
+	return d.sched
+}
+
+// rewind brings g and its instantiated subflows back to idle, keeping their tasks.
+func rewind(g *eGraph) {
+	g.reset()
+	g.run = nil
+	for _, n := range g.nodes {
+		n.state.Store(kNodeStateIdle)
+		if sf, ok := n.ptr.(*Subflow); ok && sf.g.instantiated {
+			rewind(sf.g)
+		}
+	}
+}
+
+type dryQueued struct {
+	node *innerNode
+	at   time.Duration
+}
+
+// dryTask is a task held by a worker.
+type dryTask struct {
+	node *innerNode
+	slot int // index in Schedule.Slots
+	held int // semaphores of node acquired
+	end  time.Duration
+}
+
+type dryRun struct {
+	plan    DryRunPlan
+	sched   *Schedule
+	now     time.Duration
+	ready   []dryQueued // FIFO, as the executor queue
+	busy    []bool      // by worker
+	running []*dryTask  // started tasks, ending at their end
+	tokens  map[*Semaphore]int
+	queues  map[*Semaphore][]*dryTask // tasks waiting for each semaphore
+	joins   map[*eGraph]func()        // called once each running graph has no task left
+	conds   map[*innerNode]int        // executions of each condition
+	built   []*eGraph                 // subflows instantiated by the dry run
+}
+
+// step hands ready tasks to free workers and ends the next running task, it returns false if none is left.
+func (d *dryRun) step() bool {
+	for len(d.ready) > 0 {
+		w := slices.Index(d.busy, false)
+		if w < 0 {
+			break
+		}
+		q := d.ready[0]
+		d.ready = d.ready[1:]
+		d.busy[w] = true
+		if len(d.sched.Slots) >= dryRunMaxExecutions {
+			panic(fmt.Sprintf("dry run of %q exceeds %d task executions, plan Branches must end its loops", d.sched.Flow, dryRunMaxExecutions))
+		}
+		d.sched.Slots = append(d.sched.Slots, ScheduleSlot{
+			Path:   q.node.localPath(),
+			Worker: w + 1,
+			Queued: q.at,
+			Picked: d.now,
+		})
+		d.acquire(&dryTask{node: q.node, slot: len(d.sched.Slots) - 1})
+	}
+	if len(d.running) == 0 {
+		return false
+	}
+
+	i := 0
+	for j, t := range d.running {
+		if t.end < d.running[i].end {
+			i = j
+		}
+	}
+	t := d.running[i]
+	d.running = slices.Delete(d.running, i, i+1)
+	d.now = t.end
+	d.finish(t)
+	return true
+}
+
+// acquire takes the semaphores of t in order, as Semaphore does, and starts t once it holds them all.
+func (d *dryRun) acquire(t *dryTask) {
+	for ; t.held < len(t.node.semaphores); t.held++ {
+		s := t.node.semaphores[t.held]
+		if d.tokens[s] == s.Limit() {
+			d.queues[s] = append(d.queues[s], t)
+			return
+		}
+		d.tokens[s]++
+	}
+	d.sched.Slots[t.slot].Start = d.now
+	t.end = d.now + d.plan.cost(t.node)
+	d.running = append(d.running, t)
+}
+
+func (d *dryRun) release(t *dryTask) {
+	for i := len(t.node.semaphores) - 1; i >= 0; i-- {
+		s := t.node.semaphores[i]
+		d.tokens[s]--
+		if q := d.queues[s]; len(q) > 0 {
+			d.queues[s] = q[1:]
+			d.tokens[s]++
+			q[0].held++
+			d.acquire(q[0])
+		}
+	}
+}
+
+func (d *dryRun) finish(t *dryTask) {
+	if p, ok := t.node.ptr.(*Subflow); ok {
+		if join, running := d.joins[p.g]; running {
+			// a detached subflow reached again, e.g. by a loop, holds its worker until its previous
+			// execution is done
+			d.joins[p.g] = func() {
+				join()
+				d.finish(t)
+			}
+			return
+		}
+	}
+	d.release(t)
+	node := t.node
+	end := func() {
+		slot := &d.sched.Slots[t.slot]
+		slot.End = d.now
+		d.sched.Makespan = max(d.sched.Makespan, d.now)
+		d.busy[slot.Worker-1] = false
+	}
+
+	switch p := node.ptr.(type) {
+	case *Static:
+		end()
+		node.drop()
+		d.scheSuccessors(node)
+		d.done(node.g)
+	case *Condition:
+		end()
+		choice := d.plan.branch(node, d.conds[node])
+		d.conds[node]++
+		succ, ok := p.mapper[choice]
+		if !ok {
+			panic(fmt.Sprintf("dry run: condition %q has no branch %d", node.localPath(), choice))
+		}
+		d.schedule(succ)
+		node.drop()
+		node.setup()
+		d.done(node.g)
+	case *Subflow:
+		if !p.g.instantiated {
+			p.handle(p)
+			p.g.instantiated = true
+			d.built = append(d.built, p.g)
+		}
+		p.g.run = node.g.run
+		if p.g.detached {
+			root := node.g.root()
+			root.ref()
+			node.drop()
+			d.scheSuccessors(node)
+			d.done(node.g)
+			d.scheduleGraph(p.g, func() {
+				end()
+				d.done(root)
+			})
+			return
+		}
+		// the worker of a subflow waits for its tasks
+		d.scheduleGraph(p.g, func() {
+			end()
+			node.drop()
+			d.scheSuccessors(node)
+			d.done(node.g)
+		})
+	default:
+		panic("unsupported node")
+	}
+}
+
+func (d *dryRun) scheSuccessors(node *innerNode) {
+	candidate := make([]*innerNode, 0, len(node.successors))
+	for _, n := range node.successors {
+		if n.recyclable() && n.state.Load() == kNodeStateIdle {
+			n.state.Store(kNodeStateWaiting)
+			candidate = append(candidate, n)
+		}
+	}
+
+	slices.SortFunc(candidate, func(i, j *innerNode) int {
+		return cmp.Compare(i.priority, j.priority)
+	})
+	node.setup() // make node repeatable
+	d.schedule(candidate...)
+}
+
+func (d *dryRun) schedule(nodes ...*innerNode) {
+	for _, node := range nodes {
+		if !node.g.run.selected(node) {
+			continue
+		}
+		node.g.ref()
+		d.ready = append(d.ready, dryQueued{node: node, at: d.now})
+	}
+}
+
+// scheduleGraph schedules the entries of g, join is called once g has no task left.
+func (d *dryRun) scheduleGraph(g *eGraph, join func()) {
+	g.setup()
+	slices.SortFunc(g.entries, func(i, j *innerNode) int {
+		return cmp.Compare(i.priority, j.priority)
+	})
+	d.joins[g] = join
+	d.schedule(g.entries...)
+	if g.recyclable() {
+		d.join(g)
+	}
+}
+
+func (d *dryRun) done(g *eGraph) {
+	g.deref()
+	if g.recyclable() {
+		d.join(g)
+	}
+}
+
+func (d *dryRun) join(g *eGraph) {
+	join, ok := d.joins[g]
+	if !ok {
+		return
+	}
+	delete(d.joins, g)
+	join()
+}
+
+func (p DryRunPlan) cost(node *innerNode) time.Duration {
+	if c, ok := p.Costs[node.localPath()]; ok {
+		return c
+	}
+	if node.Typ == nodeSubflow {
+		return 0
+	}
+	return p.Default
+}
+
+// branch returns the branch taken by the n-th execution of the condition node.
+func (p DryRunPlan) branch(node *innerNode, n int) uint {
+	choices := p.Branches[node.localPath()]
+	if len(choices) == 0 {
+		return 0
+	}
+	return choices[min(n, len(choices)-1)]
+}
+
+// ganttWidth is the number of columns the run spans in Schedule.String.
+const ganttWidth = 40
+
+// String draws the schedule as a Gantt chart, one line per task execution: '.' while the task waits
+// for semaphores on its worker, '#' while it runs.
+func (s *Schedule) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: makespan %v on %d workers\n", s.Flow, s.Makespan, s.Workers))
+	col := func(t time.Duration) int {
+		if s.Makespan == 0 {
+			return 0
+		}
+		return int(t * ganttWidth / s.Makespan)
+	}
+	for _, slot := range s.Slots {
+		bar := []byte(strings.Repeat(" ", ganttWidth))
+		from, start, to := col(slot.Picked), col(slot.Start), col(slot.End)
+		if to == start && start < ganttWidth {
+			to++ // too short to show otherwise
+		}
+		for i := from; i < start; i++ {
+			bar[i] = '.'
+		}
+		for i := start; i < to; i++ {
+			bar[i] = '#'
+		}
+		sb.WriteString(fmt.Sprintf("  w%d |%s| %v +%v %s\n", slot.Worker, bar, slot.Start, slot.End-slot.Start, slot.Path))
+	}
+	if len(s.Stalled) > 0 {
+		sb.WriteString(fmt.Sprintf("stalled, subflows hold every worker: %s\n", strings.Join(s.Stalled, ", ")))
+	}
+	return sb.String()
+}
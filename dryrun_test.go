@@ -0,0 +1,186 @@
+package gotaskflow_test
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gotaskflow "github.com/noneback/go-taskflow"
+	"github.com/noneback/go-taskflow/utils"
+)
+
+func TestDryRun(t *testing.T) {
+	ran := 0
+	tf := gotaskflow.NewTaskFlow("G")
+	A := tf.NewTask("A", func() { ran++ })
+	B := tf.NewTask("B", func() { ran++ })
+	C := tf.NewTask("C", func() { ran++ })
+	D := tf.NewTask("D", func() { ran++ })
+	A.Precede(B, C)
+	D.Succeed(B, C)
+	plan := gotaskflow.DryRunPlan{
+		Costs:   map[string]time.Duration{"B": 2 * time.Second, "C": 3 * time.Second},
+		Default: time.Second,
+	}
+
+	for workers, want := range map[uint]time.Duration{1: 7 * time.Second, 2: 5 * time.Second} {
+		sched := gotaskflow.NewExecutor(workers).DryRun(tf, plan)
+		if sched.Makespan != want {
+			t.Errorf("%d workers: makespan %v, want %v\n%s", workers, sched.Makespan, want, sched)
+		}
+		if len(sched.Slots) != 4 || sched.Slots[3].Path != "D" {
+			t.Errorf("%d workers: unexpected slots %+v", workers, sched.Slots)
+		}
+	}
+	if ran != 0 {
+		t.Errorf("dry run ran %d tasks", ran)
+	}
+
+	gotaskflow.NewExecutor(2).Run(tf).Wait()
+	if ran != 4 {
+		t.Errorf("run after dry runs ran %d tasks, want 4", ran)
+	}
+	utils.AssertPanics(t, "frozen flow", func() {
+		gotaskflow.NewExecutor(2).DryRun(tf, plan)
+	})
+	tf.Reset()
+	gantt := gotaskflow.NewExecutor(2).DryRun(tf, plan).String()
+	if !strings.HasPrefix(gantt, "G: makespan 5s on 2 workers\n") || !strings.Contains(gantt, "+3s C") {
+		t.Errorf("unexpected gantt:\n%s", gantt)
+	}
+}
+
+func TestDryRunLeavesFlowUnchanged(t *testing.T) {
+	var (
+		n     int
+		tasks atomic.Int32
+	)
+	tf := gotaskflow.NewTaskFlow("G")
+	A := tf.NewTask("A", func() { n = 3 })
+	S := tf.NewSubflow("S", func(sf *gotaskflow.Subflow) {
+		// built from data produced by A
+		for i := 0; i < n; i++ {
+			sf.NewTask(strconv.Itoa(i), func() { tasks.Add(1) })
+		}
+	})
+	A.Precede(S)
+
+	before := tf.Spec()
+	sched := gotaskflow.NewExecutor(2).DryRun(tf, gotaskflow.DryRunPlan{Default: time.Second})
+	if len(sched.Slots) != 2 {
+		t.Errorf("expected A and S with no task, got %+v", sched.Slots)
+	}
+	if after := tf.Spec(); !reflect.DeepEqual(before, after) {
+		t.Errorf("dry run changed the flow %+v into %+v", before, after)
+	}
+
+	gotaskflow.NewExecutor(2).Run(tf).Wait()
+	if tasks.Load() != 3 {
+		t.Errorf("subflow ran %d tasks, want it instantiated after A with 3", tasks.Load())
+	}
+}
+
+func TestDryRunSemaphore(t *testing.T) {
+	tf := gotaskflow.NewTaskFlow("G")
+	sem := gotaskflow.NewSemaphore(1)
+	for _, name := range []string{"A", "B", "C"} {
+		tf.NewTask(name, func() {}).Acquire(sem)
+	}
+
+	sched := gotaskflow.NewExecutor(3).DryRun(tf, gotaskflow.DryRunPlan{Default: time.Second})
+	if sched.Makespan != 3*time.Second {
+		t.Errorf("makespan %v, want 3s\n%s", sched.Makespan, sched)
+	}
+	for _, s := range sched.Slots {
+		if s.Picked != 0 || s.End-s.Start != time.Second {
+			t.Errorf("unexpected slot %+v", s)
+		}
+	}
+}
+
+func TestDryRunBranches(t *testing.T) {
+	tf := gotaskflow.NewTaskFlow("G")
+	init := tf.NewTask("init", func() {})
+	cond := tf.NewCondition("cond", func() uint { panic("condition run") })
+	body := tf.NewTask("body", func() {})
+	back := tf.NewCondition("back", func() uint { panic("condition run") })
+	done := tf.NewTask("done", func() {})
+	init.Precede(cond)
+	cond.Precede(body, done)
+	body.Precede(back)
+	back.Precede(cond)
+
+	sched := gotaskflow.NewExecutor(2).DryRun(tf, gotaskflow.DryRunPlan{
+		Default:  time.Second,
+		Branches: map[string][]uint{"cond": {0, 0, 1}},
+	})
+	var paths []string
+	for _, s := range sched.Slots {
+		paths = append(paths, s.Path)
+	}
+	want := []string{"init", "cond", "body", "back", "cond", "body", "back", "cond", "done"}
+	if !slices.Equal(paths, want) || sched.Makespan != 9*time.Second {
+		t.Errorf("got %v in %v, want %v in 9s", paths, sched.Makespan, want)
+	}
+}
+
+func TestDryRunSubflowStalls(t *testing.T) {
+	tf := gotaskflow.NewTaskFlow("G")
+	tf.NewSubflow("S", func(sf *gotaskflow.Subflow) {
+		sf.NewTask("x", func() {})
+	})
+
+	sched := gotaskflow.NewExecutor(1).DryRun(tf, gotaskflow.DryRunPlan{Default: time.Second})
+	if !slices.Equal(sched.Stalled, []string{"S/x"}) {
+		t.Errorf("expected S/x to stall on one worker, got %v", sched.Stalled)
+	}
+
+	sched = gotaskflow.NewExecutor(2).DryRun(tf, gotaskflow.DryRunPlan{Default: time.Second})
+	if len(sched.Stalled) != 0 || sched.Makespan != time.Second {
+		t.Errorf("stalled %v, makespan %v\n%s", sched.Stalled, sched.Makespan, sched)
+	}
+	if s := sched.Slots[0]; s.Path != "S" || s.Worker != 1 || s.End != time.Second {
+		t.Errorf("subflow should hold its worker until its tasks are done, got %+v", s)
+	}
+}
+
+func TestDryRunDetached(t *testing.T) {
+	tf := gotaskflow.NewTaskFlow("G")
+	S := tf.NewSubflow("S", func(sf *gotaskflow.Subflow) {
+		sf.Detach()
+		sf.NewTask("x", func() {})
+	})
+	B := tf.NewTask("B", func() {})
+	S.Precede(B)
+
+	sched := gotaskflow.NewExecutor(3).DryRun(tf, gotaskflow.DryRunPlan{
+		Costs:   map[string]time.Duration{"S/x": 5 * time.Second},
+		Default: time.Second,
+	})
+	if sched.Makespan != 5*time.Second {
+		t.Errorf("makespan %v, want 5s\n%s", sched.Makespan, sched)
+	}
+	for _, s := range sched.Slots {
+		if s.Path == "B" && s.Start != 0 {
+			t.Errorf("successor of a detached subflow started at %v, want 0", s.Start)
+		}
+	}
+
+	var runs atomic.Int32
+	sched = gotaskflow.NewExecutor(4).DryRun(newDetachedLoop(&runs, false, 0), gotaskflow.DryRunPlan{
+		Costs:    map[string]time.Duration{"S/x": 5 * time.Second},
+		Default:  time.Second,
+		Branches: map[string][]uint{"C": {0, 0, 1}},
+	})
+	var paths []string
+	for _, s := range sched.Slots {
+		paths = append(paths, s.Path)
+	}
+	if strings.Count(strings.Join(paths, ","), "S/x") != 3 || sched.Makespan != 16*time.Second {
+		t.Errorf("got %v in %v, want S/x 3 times in 16s\n%s", paths, sched.Makespan, sched)
+	}
+}
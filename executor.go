@@ -8,16 +8,26 @@ import (
 	"runtime/debug"
 	"slices"
 	"sync"
+	"time"
 
 	"github.com/noneback/go-taskflow/utils"
 )
 
 // Executor schedule and execute taskflow
 type Executor interface {
-	Wait()                     // Wait block until all tasks finished
-	Profile(w io.Writer) error // Profile write flame graph raw text into w
-	Trace(w io.Writer) error   // Trace write Chrome Trace Event data into w
-	Run(tf *TaskFlow) Executor // Run start to schedule and execute taskflow
+	Wait()                                              // Wait block until all tasks finished
+	Profile(w io.Writer, format ...ProfileFormat) error // Profile write flame graph raw text, or the given format, into w
+	Trace(w io.Writer) error                            // Trace write Chrome Trace Event data into w
+	Run(tf *TaskFlow) Executor                          // Run start to schedule and execute taskflow
+	// Resume runs taskflow as the run identified by runID, skipping task executions
+	// a previous attempt of that run already checkpointed. Requires WithCheckpoint.
+	Resume(tf *TaskFlow, runID string) Executor
+	// RunTargets runs the tasks named targets and their transitive predecessors, skipping the others.
+	RunTargets(tf *TaskFlow, targets ...string) Executor
+	// RunFrom runs task and its transitive successors, assuming its predecessors already succeeded.
+	RunFrom(tf *TaskFlow, task *Task) Executor
+	// DryRun predicts the schedule of taskflow from the estimates of plan, without running its tasks.
+	DryRun(tf *TaskFlow, plan DryRunPlan) *Schedule
 }
 
 type innerExecutorImpl struct {
@@ -27,6 +37,10 @@ type innerExecutorImpl struct {
 	wg          *sync.WaitGroup
 	obs         *observer
 	mu          *sync.Mutex
+	ckpt        CheckpointStore
+	validate    bool // validate graphs before running them
+	cache       TaskCache
+	rebuild     bool // instantiate every subflow on each execution
 }
 
 // NewExecutor returns an Executor with the specified concurrency and options.
@@ -51,9 +65,44 @@ func NewExecutor(concurrency uint, opts ...Option) Executor {
 
 // Run start to schedule and execute taskflow
 func (e *innerExecutorImpl) Run(tf *TaskFlow) Executor {
+	e.run(tf, newRunState(tf, ""))
+	return e
+}
+
+// Resume runs taskflow as the run identified by runID, skipping checkpointed task executions.
+// A run with no checkpoint yet starts from scratch. If the checkpoint cannot be loaded, nothing is executed.
+func (e *innerExecutorImpl) Resume(tf *TaskFlow, runID string) Executor {
+	if e.ckpt == nil {
+		panic("checkpoint store is not configured, use WithCheckpoint")
+	}
+	recs, err := e.ckpt.Load(runID)
+	if err != nil {
+		log.Printf("[go-taskflow] cannot resume run %q of graph %q: %v", runID, tf.Name(), err)
+		return e
+	}
+
+	rs := newRunState(tf, runID)
+	rs.ckpt = newCheckpointRun(runID, e.ckpt, recs)
+	e.run(tf, rs)
+	return e
+}
+
+func (e *innerExecutorImpl) run(tf *TaskFlow, rs *runState) {
+	if e.validate {
+		if err := tf.Validate(); err != nil {
+			log.Printf("[go-taskflow] graph %q not run: %v", tf.Name(), err)
+			e.obs.runRejected(rs.info, err)
+			return
+		}
+	}
 	tf.frozen = true
+	tf.graph.run = rs
+	rs.info.Begin = time.Now()
+	e.obs.runStart(rs.info)
 	e.scheduleGraph(nil, tf.graph, nil)
-	return e
+	rs.info.Cost = time.Since(rs.info.Begin)
+	rs.info.Canceled = tf.graph.canceled.Load()
+	e.obs.runEnd(rs.info)
 }
 
 func (e *innerExecutorImpl) invokeGraph(g *eGraph, parentSpan *span) bool {
@@ -113,16 +162,18 @@ func getDependentNames(node *innerNode) []string {
 	return names
 }
 
-func (e *innerExecutorImpl) invokeStatic(node *innerNode, parentSpan *span, p *Static) func() {
-	return func() {
-		s := e.obs.openSpan(node, parentSpan)
+func (e *innerExecutorImpl) invokeStatic(node *innerNode, parentSpan *span, p *Static) func(worker int) {
+	return func(worker int) {
+		s := e.obs.openSpan(node, parentSpan, worker)
+		outcome := TaskCanceled
 		defer func() {
 			r := recover()
 			if r != nil {
 				node.g.canceled.Store(true)
+				outcome = TaskFailed
 				log.Printf("[go-taskflow] graph %q canceled: static task %q panicked: %v\n%s", node.g.name, node.name, r, debug.Stack())
 			}
-			e.obs.closeSpan(s, r == nil)
+			e.obs.endSpan(s, outcome)
 			node.drop()
 			e.sche_successors(node)
 			node.g.deref()
@@ -130,24 +181,63 @@ func (e *innerExecutorImpl) invokeStatic(node *innerNode, parentSpan *span, p *S
 		}()
 		if !node.g.canceled.Load() {
 			node.state.Store(kNodeStateRunning)
-			p.handle()
+			outcome = TaskRestored
+			if rec, done := node.g.run.replay(node); !done {
+				outcome = TaskFinished
+				if e.cached(node, func() { node.hold(p.fn(e, node, s, worker)) }) {
+					outcome = TaskCached
+				}
+				node.g.run.record(node, rec)
+			}
 			node.state.Store(kNodeStateFinished)
 		}
 	}
 }
 
-func (e *innerExecutorImpl) invokeSubflow(node *innerNode, parentSpan *span, p *Subflow) func() {
-	return func() {
-		s := e.obs.openSpan(node, parentSpan)
+func (e *innerExecutorImpl) invokeSubflow(node *innerNode, parentSpan *span, p *Subflow) func(worker int) {
+	return func(worker int) {
+		// a detached subflow reached again, e.g. by a loop, waits for its previous execution
+		p.g.detachedRun.Wait()
+		s := e.obs.openSpan(node, parentSpan, worker)
+		var (
+			rec     CheckpointRecord
+			done    bool // completed by a previous attempt of the run
+			outcome = TaskCanceled
+		)
 		defer func() {
 			r := recover()
 			if r != nil {
 				log.Printf("[go-taskflow] graph %q canceled: subflow %q panicked: %v\n%s", node.g.name, node.name, r, debug.Stack())
 				node.g.canceled.Store(true)
 				p.g.canceled.Store(true)
+				outcome = TaskFailed
+			}
+			e.obs.endSpan(s, outcome)
+			if !done && r == nil && p.g.detached {
+				// the run, rather than the graph of the subflow, waits for its tasks
+				root := node.g.root()
+				root.ref()
+				p.g.detachedRun.Add(1)
+				node.drop()
+				e.sche_successors(node)
+				node.g.deref()
+				p.g.run = node.g.run
+				e.scheduleGraph(root, p.g, s)
+				if !p.g.canceled.Load() && !root.canceled.Load() {
+					node.g.run.record(node, rec)
+				}
+				p.g.detachedRun.Done()
+				root.deref()
+				e.wg.Done()
+				return
+			}
+			if !done {
+				p.g.run = node.g.run
+				e.scheduleGraph(node.g, p.g, s)
+				if r == nil && !p.g.canceled.Load() && !node.g.canceled.Load() {
+					node.g.run.record(node, rec)
+				}
 			}
-			e.obs.closeSpan(s, r == nil)
-			e.scheduleGraph(node.g, p.g, s)
 			node.drop()
 			e.sche_successors(node)
 			node.g.deref()
@@ -156,25 +246,44 @@ func (e *innerExecutorImpl) invokeSubflow(node *innerNode, parentSpan *span, p *
 
 		if !node.g.canceled.Load() {
 			node.state.Store(kNodeStateRunning)
-			if !p.g.instantiated {
-				p.handle(p)
+			outcome = TaskRestored
+			if rec, done = node.g.run.replay(node); !done {
+				if p.stale(e.rebuild) {
+					node.g.run.forget(p.g)
+					p.g.clear() // discard the tasks of the previous execution
+					node.hold(func() { p.handle(p) })
+					p.g.instantiated = true
+					if e.validate {
+						if err := p.g.validate(); err != nil {
+							panic(err)
+						}
+					}
+				}
+				outcome = TaskFinished
 			}
-			p.g.instantiated = true
 			node.state.Store(kNodeStateFinished)
 		}
 	}
 }
 
-func (e *innerExecutorImpl) invokeCondition(node *innerNode, parentSpan *span, p *Condition) func() {
-	return func() {
-		s := e.obs.openSpan(node, parentSpan)
+func (e *innerExecutorImpl) invokeCondition(node *innerNode, parentSpan *span, p *Condition) func(worker int) {
+	return func(worker int) {
+		s := e.obs.openSpan(node, parentSpan, worker)
+		var (
+			choice  uint
+			outcome = TaskCanceled
+		)
 		defer func() {
 			r := recover()
 			if r != nil {
 				node.g.canceled.Store(true)
+				outcome = TaskFailed
 				log.Printf("[go-taskflow] graph %q canceled: condition task %q panicked: %v\n%s", node.g.name, node.name, r, debug.Stack())
 			}
-			e.obs.closeSpan(s, r == nil)
+			if outcome == TaskFinished || outcome == TaskRestored {
+				e.obs.chose(s, choice)
+			}
+			e.obs.endSpan(s, outcome)
 			node.drop()
 			// e.sche_successors(node)
 			node.g.deref()
@@ -185,9 +294,17 @@ func (e *innerExecutorImpl) invokeCondition(node *innerNode, parentSpan *span, p
 		if !node.g.canceled.Load() {
 			node.state.Store(kNodeStateRunning)
 
-			choice := p.handle()
-			if choice > uint(len(p.mapper)) {
-				panic(fmt.Sprintln("condition task failed, successors of condition should be more than precondition choice", p.handle()))
+			rec, done := node.g.run.replay(node)
+			choice = rec.Choice
+			outcome = TaskRestored
+			if !done {
+				node.hold(func() { choice = p.handle() })
+				if choice > uint(len(p.mapper)) {
+					panic(fmt.Sprintln("condition task failed, successors of condition should be more than precondition choice", choice))
+				}
+				rec.Choice = choice
+				node.g.run.record(node, rec)
+				outcome = TaskFinished
 			}
 			// do choice and cancel others
 			node.state.Store(kNodeStateFinished)
@@ -197,13 +314,18 @@ func (e *innerExecutorImpl) invokeCondition(node *innerNode, parentSpan *span, p
 }
 
 func (e *innerExecutorImpl) invokeNode(node *innerNode, parentSpan *span) {
+	e.pool.GoWorker(e.task(node, parentSpan))
+}
+
+// task returns the function executing node on a worker.
+func (e *innerExecutorImpl) task(node *innerNode, parentSpan *span) func(worker int) {
 	switch p := node.ptr.(type) {
 	case *Static:
-		e.pool.Go(e.invokeStatic(node, parentSpan, p))
+		return e.invokeStatic(node, parentSpan, p)
 	case *Subflow:
-		e.pool.Go(e.invokeSubflow(node, parentSpan, p))
+		return e.invokeSubflow(node, parentSpan, p)
 	case *Condition:
-		e.pool.Go(e.invokeCondition(node, parentSpan, p))
+		return e.invokeCondition(node, parentSpan, p)
 	default:
 		panic("unsupported node")
 	}
@@ -224,10 +346,14 @@ func (e *innerExecutorImpl) schedule(nodes ...*innerNode) {
 			node.g.scheCond.L.Unlock()
 			return
 		}
+		if !node.g.run.selected(node) {
+			continue
+		}
 		e.wg.Add(1)
 		node.g.scheCond.L.Lock()
 
 		node.g.ref()
+		e.obs.scheduled(node)
 		e.pushIntoQueue(node)
 
 		node.g.scheCond.Signal()
@@ -253,11 +379,14 @@ func (e *innerExecutorImpl) Wait() {
 	e.wg.Wait()
 }
 
-// Profile write flame graph raw text into w
-func (e *innerExecutorImpl) Profile(w io.Writer) error {
+// Profile write flame graph raw text into w, or a pprof profile if format is ProfilePprof
+func (e *innerExecutorImpl) Profile(w io.Writer, format ...ProfileFormat) error {
 	if e.obs.profiler == nil {
 		return nil
 	}
+	if len(format) > 0 && format[0] == ProfilePprof {
+		return e.obs.profiler.drawPprof(w)
+	}
 	return e.obs.profiler.draw(w)
 }
 
@@ -1,9 +1,14 @@
 package gotaskflow_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -91,3 +96,156 @@ func TestPanicInSubflow(t *testing.T) {
 	make_install.Precede(relink)
 	executor.Run(tf).Wait()
 }
+
+func TestSubflowRebuild(t *testing.T) {
+	var (
+		batches = [][]string{{"a"}, {"b", "c"}}
+		i       int
+		mu      sync.Mutex
+		ran     []string
+	)
+	tf := gotaskflow.NewTaskFlow("G")
+	init := tf.NewTask("init", func() { i = 0 })
+	sub := tf.NewSubflow("S", func(sf *gotaskflow.Subflow) {
+		for _, name := range batches[i] {
+			name := name
+			sf.NewTask(name, func() {
+				mu.Lock()
+				defer mu.Unlock()
+				ran = append(ran, name)
+			})
+		}
+	}).Rebuild()
+	next := tf.NewCondition("next", func() uint {
+		i++
+		if i < len(batches) {
+			return 0
+		}
+		return 1
+	})
+	done := tf.NewTask("done", func() {})
+	init.Precede(sub)
+	sub.Precede(next)
+	next.Precede(sub, done)
+
+	executor := gotaskflow.NewExecutor(4, gotaskflow.WithTracer())
+	executor.Run(tf).Wait()
+	slices.Sort(ran)
+	if !slices.Equal(ran, []string{"a", "b", "c"}) {
+		t.Errorf("rebuilt subflow ran %v, want [a b c]", ran)
+	}
+
+	var buf bytes.Buffer
+	if err := executor.Trace(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var events []struct {
+		Name string            `json:"name"`
+		Ph   string            `json:"ph"`
+		Args map[string]string `json:"args"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &events); err != nil {
+		t.Fatal(err)
+	}
+	var iterations []string
+	for _, ev := range events {
+		if ev.Ph == "X" && ev.Name == "S" {
+			iterations = append(iterations, ev.Args["iteration"])
+		}
+	}
+	if !slices.Equal(iterations, []string{"", "1"}) {
+		t.Errorf("subflow executions traced with iterations %q, want [\"\" \"1\"]", iterations)
+	}
+}
+
+func TestWithSubflowRebuild(t *testing.T) {
+	for _, rebuild := range []bool{false, true} {
+		builds := 0
+		tf := gotaskflow.NewTaskFlow("G")
+		tf.NewSubflow("S", func(sf *gotaskflow.Subflow) {
+			builds++
+			sf.NewTask("x", func() {})
+		})
+
+		var opts []gotaskflow.Option
+		if rebuild {
+			opts = append(opts, gotaskflow.WithSubflowRebuild())
+		}
+		executor := gotaskflow.NewExecutor(4, opts...)
+		executor.Run(tf).Wait()
+		executor.Run(tf).Wait()
+		if want := map[bool]int{false: 1, true: 2}[rebuild]; builds != want {
+			t.Errorf("rebuild %v: subflow built %d times, want %d", rebuild, builds, want)
+		}
+	}
+}
+
+func TestDetachedSubflow(t *testing.T) {
+	var (
+		release = make(chan struct{})
+		slow    atomic.Bool
+	)
+	tf := gotaskflow.NewTaskFlow("G")
+	S := tf.NewSubflow("S", func(sf *gotaskflow.Subflow) {
+		sf.Detach()
+		sf.NewTask("slow", func() {
+			<-release
+			slow.Store(true)
+		})
+	})
+	B := tf.NewTask("B", func() { close(release) })
+	S.Precede(B)
+
+	finished := make(chan struct{})
+	go func() {
+		gotaskflow.NewExecutor(4).Run(tf).Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("successor of a detached subflow waited for its tasks")
+	}
+	if !slow.Load() {
+		t.Error("run ended before the tasks of the detached subflow")
+	}
+}
+
+// newDetachedLoop returns a flow reaching a detached subflow 3 times through a condition loop,
+// counting the executions of its task, which takes delay.
+func newDetachedLoop(runs *atomic.Int32, rebuild bool, delay time.Duration) *gotaskflow.TaskFlow {
+	i := 0
+	tf := gotaskflow.NewTaskFlow("G")
+	A0 := tf.NewTask("A0", func() { i = 0 })
+	S := tf.NewSubflow("S", func(sf *gotaskflow.Subflow) {
+		sf.Detach()
+		sf.NewTask("x", func() {
+			time.Sleep(delay)
+			runs.Add(1)
+		})
+	})
+	if rebuild {
+		S.Rebuild()
+	}
+	C := tf.NewCondition("C", func() uint {
+		i++
+		if i < 3 {
+			return 0
+		}
+		return 1
+	})
+	A0.Precede(S)
+	S.Precede(C)
+	C.Precede(S, tf.NewTask("E", func() {}))
+	return tf
+}
+
+func TestDetachedSubflowLoop(t *testing.T) {
+	for _, rebuild := range []bool{false, true} {
+		var runs atomic.Int32
+		runWithin(t, gotaskflow.NewExecutor(4), newDetachedLoop(&runs, rebuild, 10*time.Millisecond))
+		if runs.Load() != 3 {
+			t.Errorf("rebuild %v: detached subflow task ran %d times, want 3", rebuild, runs.Load())
+		}
+	}
+}
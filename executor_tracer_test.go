@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -31,8 +32,8 @@ func TestExecutorWithTracer(t *testing.T) {
 	if err := json.Unmarshal(buf.Bytes(), &events); err != nil {
 		t.Fatalf("Trace output is not valid JSON: %v", err)
 	}
-	if len(events) != 3 {
-		t.Errorf("expected 3 trace events, got %d", len(events))
+	if n := countPhase(events, "X"); n != 3 {
+		t.Errorf("expected 3 trace events, got %d", n)
 	}
 	t.Logf(buf.String())
 }
@@ -158,8 +159,41 @@ func TestExecutorTraceComplex(t *testing.T) {
 	}
 
 	// prepare + read_config + load_data + validate + check + sub_process + transform + enrich + aggregate + report = 10
-	if len(events) != 10 {
-		t.Errorf("expected 10 trace events, got %d", len(events))
+	if n := countPhase(events, "X"); n != 10 {
+		t.Errorf("expected 10 trace events, got %d", n)
+	}
+
+	// one arrow per dependency edge taken, the fallback branch did not run
+	if s, f := countPhase(events, "s"), countPhase(events, "f"); s != 9 || f != 9 {
+		t.Errorf("expected 9 flow arrows, got %d starts and %d finishes", s, f)
+	}
+
+	rows, named := map[float64]bool{}, map[float64]bool{}
+	for _, ev := range events {
+		switch ev["ph"] {
+		case "X":
+			if tid := ev["tid"].(float64); tid < 1 || tid > 8 {
+				t.Errorf("task %v ran on unknown worker %v", ev["name"], tid)
+			} else {
+				rows[tid] = true
+			}
+		case "i":
+			if ev["name"] != "check_quality" || ev["args"].(map[string]interface{})["branch"] != "0" {
+				t.Errorf("unexpected instant event %v", ev)
+			}
+		case "M":
+			if ev["name"] == "thread_name" {
+				named[ev["tid"].(float64)] = true
+			}
+		}
+	}
+	for tid := range rows {
+		if !named[tid] {
+			t.Errorf("worker row %v without thread_name metadata", tid)
+		}
+	}
+	if countPhase(events, "i") != 1 {
+		t.Errorf("expected one branch event, got %d", countPhase(events, "i"))
 	}
 
 	t.Log("=== Complex Pipeline Chrome Trace JSON ===")
@@ -192,7 +226,60 @@ func TestExecutorWithBothProfilerAndTracer(t *testing.T) {
 	if err := json.Unmarshal(traceBuf.Bytes(), &events); err != nil {
 		t.Fatalf("Trace output is not valid JSON: %v", err)
 	}
-	if len(events) != 2 {
-		t.Errorf("expected 2 trace events, got %d", len(events))
+	if n := countPhase(events, "X"); n != 2 {
+		t.Errorf("expected 2 trace events, got %d", n)
+	}
+}
+
+func countPhase(events []map[string]interface{}, ph string) int {
+	n := 0
+	for _, ev := range events {
+		if ev["ph"] == ph {
+			n++
+		}
+	}
+	return n
+}
+
+func TestAnalyzeTrace(t *testing.T) {
+	executor := gotaskflow.NewExecutor(4, gotaskflow.WithTracer())
+	tf := gotaskflow.NewTaskFlow("G")
+	A := tf.NewTask("A", func() { time.Sleep(5 * time.Millisecond) })
+	S := tf.NewSubflow("S", func(sf *gotaskflow.Subflow) {
+		slow := sf.NewTask("slow", func() { time.Sleep(40 * time.Millisecond) })
+		fast := sf.NewTask("fast", func() { time.Sleep(time.Millisecond) })
+		end := sf.NewTask("end", func() {})
+		slow.Precede(end)
+		fast.Precede(end)
+	})
+	side := tf.NewTask("side", func() { time.Sleep(time.Millisecond) })
+	B := tf.NewTask("B", func() {})
+	A.Precede(S, side)
+	S.Precede(B)
+	side.Precede(B)
+	executor.Run(tf).Wait()
+
+	var buf bytes.Buffer
+	if err := executor.Trace(&buf); err != nil {
+		t.Fatal(err)
+	}
+	a, err := gotaskflow.Analyze(&buf, tf, 4)
+	if err != nil {
+		t.Fatalf("Analyze error: %v", err)
+	}
+
+	var path []string
+	for _, s := range a.CriticalPath {
+		path = append(path, s.Path)
+	}
+	if got := strings.Join(path, ","); got != "A,S,S/slow,S/end,B" {
+		t.Errorf("expected critical path A,S,S/slow,S/end,B, got %s", got)
+	}
+	if a.Slack["side"] < 30*time.Millisecond {
+		t.Errorf("expected side to have slack, got %v", a.Slack["side"])
+	}
+	if a.Workers != 4 || a.Efficiency <= 0 || a.Efficiency > 1 {
+		t.Errorf("unexpected workers %d, efficiency %v", a.Workers, a.Efficiency)
 	}
+	t.Log(a)
 }
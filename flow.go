@@ -12,18 +12,34 @@ type Condition struct {
 
 // Static Wrapper
 type Static struct {
-	handle func()
+	handle  func()
+	runtime func(rt *Runtime) // set instead of handle for tasks created by NewRuntimeTask
 }
 
 // Subflow Wrapper
 type Subflow struct {
-	handle func(sf *Subflow)
-	g      *eGraph
+	handle  func(sf *Subflow)
+	g       *eGraph
+	rebuild bool // instantiate on every execution, see Task.Rebuild
+}
+
+// Detach lets the successors of the subflow run once it is instantiated, rather than once its tasks are done,
+// for long-running background work. The run still waits for its tasks, and a panic in them cancels the run.
+// Reached again while its tasks run, e.g. by a loop, the subflow waits for them first.
+// It is called by the instantiate func.
+func (sf *Subflow) Detach() {
+	sf.g.detached = true
+}
+
+// stale tells whether sf must be instantiated for its next execution, which always holds if rebuild.
+func (sf *Subflow) stale(rebuild bool) bool {
+	return !sf.g.instantiated || rebuild || sf.rebuild
 }
 
 // Push pushs all tasks into subflow
 func (sf *Subflow) push(tasks ...*Task) {
 	for _, task := range tasks {
+		task.node.task = task
 		sf.g.push(task.node)
 	}
 }
@@ -37,12 +53,20 @@ func (tf *flowBuilder) NewStatic(name string, f func()) *innerNode {
 	return node
 }
 
+func (fb *flowBuilder) NewRuntimeStatic(name string, f func(rt *Runtime)) *innerNode {
+	node := fb.NewStatic(name, nil)
+	node.ptr.(*Static).runtime = f
+	return node
+}
+
 func (fb *flowBuilder) NewSubflow(name string, f func(sf *Subflow)) *innerNode {
 	node := newNode(name)
-	node.ptr = &Subflow{
+	sf := &Subflow{
 		handle: f,
 		g:      newGraph(name),
 	}
+	sf.g.parent = node
+	node.ptr = sf
 	node.Typ = nodeSubflow
 	return node
 }
@@ -66,6 +90,15 @@ func (sf *Subflow) NewTask(name string, f func()) *Task {
 	return task
 }
 
+// NewRuntimeTask returns a static task receiving the Runtime of its executor.
+func (sf *Subflow) NewRuntimeTask(name string, f func(rt *Runtime)) *Task {
+	task := &Task{
+		node: builder.NewRuntimeStatic(name, f),
+	}
+	sf.push(task)
+	return task
+}
+
 // NewSubflow returns a subflow task
 func (sf *Subflow) NewSubflow(name string, f func(sf *Subflow)) *Task {
 	task := &Task{
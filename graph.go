@@ -1,6 +1,7 @@
 package gotaskflow
 
 import (
+	"fmt"
 	"sync"
 	"sync/atomic"
 )
@@ -13,6 +14,12 @@ type eGraph struct { // execution graph
 	scheCond     *sync.Cond
 	instantiated bool
 	canceled     atomic.Bool // only changes when task in graph panic
+	parent       *innerNode  // subflow node owning this graph, nil for a TaskFlow
+	run          *runState
+	unique       bool // reject tasks named like another task of the graph, see TaskFlow.RequireUniqueNames
+	detached     bool // successors of the subflow do not wait for the graph, see Subflow.Detach
+
+	detachedRun sync.WaitGroup // pending while a detached execution of the graph runs
 }
 
 func newGraph(name string) *eGraph {
@@ -44,25 +51,121 @@ func (g *eGraph) reset() {
 	}
 }
 
+// restore brings g back to its state before its first run: subflows lose the tasks they instantiated.
+func (g *eGraph) restore() {
+	g.reset()
+	g.canceled.Store(false)
+	g.run = nil
+	for _, n := range g.nodes {
+		n.state.Store(kNodeStateIdle)
+		if sf, ok := n.ptr.(*Subflow); ok {
+			sf.g.clear()
+		}
+	}
+}
+
+// clear removes every task of g, and of its subflows.
+func (g *eGraph) clear() {
+	for _, n := range g.nodes {
+		if sf, ok := n.ptr.(*Subflow); ok {
+			sf.g.clear()
+		}
+		n.successors, n.dependents = nil, nil
+		n.g = nil
+	}
+	g.nodes = nil
+	g.instantiated = false
+	g.detached = false
+	g.restore()
+}
+
+// remove detaches n and its edges from g, renumbering the branches of conditions preceding n.
+func (g *eGraph) remove(n *innerNode) {
+	if sf, ok := n.ptr.(*Subflow); ok {
+		sf.g.clear()
+	}
+	for _, succ := range n.successors {
+		succ.dependents = removeNode(succ.dependents, n)
+	}
+	for _, dep := range n.dependents {
+		dep.successors = removeNode(dep.successors, n)
+		dep.remap()
+	}
+	n.successors, n.dependents = nil, nil
+	g.nodes = removeNode(g.nodes, n)
+	n.g = nil
+}
+
+// removeNode removes every occurrence of n from nodes.
+func removeNode(nodes []*innerNode, n *innerNode) []*innerNode {
+	kept := nodes[:0]
+	for _, node := range nodes {
+		if node != n {
+			kept = append(kept, node)
+		}
+	}
+	for i := len(kept); i < len(nodes); i++ {
+		nodes[i] = nil
+	}
+	return kept
+}
+
 func (g *eGraph) push(n ...*innerNode) {
-	g.nodes = append(g.nodes, n...)
 	for _, node := range n {
+		if g.uniqueNames() && g.find(node.name) != nil {
+			panic(fmt.Sprintf("duplicate task name %q in %q", node.name, g.path()))
+		}
+		g.nodes = append(g.nodes, node)
 		node.g = g
 	}
 }
 
+// find returns the task of g named name, nil if there is none.
+func (g *eGraph) find(name string) *innerNode {
+	for _, n := range g.nodes {
+		if n.name == name {
+			return n
+		}
+	}
+	return nil
+}
+
+// uniqueNames tells whether g or the graph enclosing it requires unique task names.
+func (g *eGraph) uniqueNames() bool {
+	if g.unique {
+		return true
+	}
+	return g.parent != nil && g.parent.g != nil && g.parent.g.uniqueNames()
+}
+
+// path returns the names of the flow and subflows g belongs to, joined by "/".
+func (g *eGraph) path() string {
+	if g.parent == nil {
+		return g.name
+	}
+	return g.parent.path()
+}
+
 func (g *eGraph) setup() {
 	g.reset()
 
 	for _, node := range g.nodes {
 		node.setup()
 
-		if len(node.dependents) == 0 {
+		if g.run.entry(node) {
 			g.entries = append(g.entries, node)
 		}
 	}
 }
 
+// root returns the graph of the TaskFlow g belongs to.
+func (g *eGraph) root() *eGraph {
+	for g.parent != nil && g.parent.g != nil {
+		g = g.parent.g
+	}
+	return g
+}
+
 func (g *eGraph) recyclable() bool {
 	return g.joinCounter.Load() == 0
 }
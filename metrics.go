@@ -0,0 +1,311 @@
+package gotaskflow
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// defaultDurationBuckets are the upper bounds, in seconds, of the task duration histogram.
+var defaultDurationBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Metrics collects executor metrics and exposes them in the Prometheus text exposition format.
+// Register it with WithMetrics; one Metrics may be shared by several executors.
+// Task labels set by Task.Label are exported as labels prefixed with "label_", e.g. label_owner.
+// Keys sanitized to the same name are told apart by a numeric suffix in key order, e.g. label_a_b and
+// label_a_b_2 for the keys "a-b" and "a_b".
+type Metrics struct {
+	mu        *sync.Mutex
+	started   map[taskLabels]uint64
+	finished  map[taskLabels]uint64
+	failed    map[taskLabels]uint64
+	skipped   map[skipLabels]uint64
+	durations map[taskLabels]*histogram
+	inFlight  map[string]int64 // runs in flight per flow
+	executors []*innerExecutorImpl
+}
+
+type taskLabels struct {
+	flow, task, typ string
+	labels          string // label name/value pairs of the task, sorted and joined by "\x00"
+}
+
+type skipLabels struct {
+	taskLabels
+	reason string
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative
+	sum    float64
+	count  uint64
+}
+
+// NewMetrics returns an empty Metrics.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		mu:        &sync.Mutex{},
+		started:   make(map[taskLabels]uint64),
+		finished:  make(map[taskLabels]uint64),
+		failed:    make(map[taskLabels]uint64),
+		skipped:   make(map[skipLabels]uint64),
+		durations: make(map[taskLabels]*histogram),
+		inFlight:  make(map[string]int64),
+	}
+}
+
+func (m *Metrics) bind(e *innerExecutorImpl) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.executors = append(m.executors, e)
+}
+
+func labelsOf(info *TaskInfo) taskLabels {
+	l := taskLabels{task: info.Name, typ: info.Type}
+	if info.Run != nil {
+		l.flow = info.Run.Flow
+	}
+	if len(info.Labels) > 0 {
+		keys := make([]string, 0, len(info.Labels))
+		for k := range info.Labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, 0, 2*len(keys))
+		names := make(map[string]bool, len(keys))
+		for _, k := range keys {
+			name := promLabelName(k)
+			for i := 2; names[name]; i++ {
+				name = promLabelName(k) + "_" + strconv.Itoa(i)
+			}
+			names[name] = true
+			pairs = append(pairs, name, info.Labels[k])
+		}
+		l.labels = strings.Join(pairs, "\x00")
+	}
+	return l
+}
+
+// promLabelName turns a task label key into a Prometheus label name.
+func promLabelName(key string) string {
+	return "label_" + strings.Map(func(r rune) rune {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, key)
+}
+
+// OnRunStart implements Observer.
+func (m *Metrics) OnRunStart(run *RunInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight[run.Flow]++
+}
+
+// OnTaskScheduled implements Observer.
+func (m *Metrics) OnTaskScheduled(*TaskInfo) {}
+
+// OnTaskStart implements Observer.
+func (m *Metrics) OnTaskStart(info *TaskInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.started[labelsOf(info)]++
+}
+
+// OnTaskEnd implements Observer.
+func (m *Metrics) OnTaskEnd(info *TaskInfo, outcome TaskOutcome) {
+	l := labelsOf(info)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch outcome {
+	case TaskFinished:
+		m.finished[l]++
+		h, ok := m.durations[l]
+		if !ok {
+			h = &histogram{counts: make([]uint64, len(defaultDurationBuckets))}
+			m.durations[l] = h
+		}
+		h.observe(info.Cost.Seconds())
+	case TaskFailed:
+		m.failed[l]++
+	default:
+		m.skipped[skipLabels{taskLabels: l, reason: outcome.String()}]++
+	}
+}
+
+// OnRunEnd implements Observer.
+func (m *Metrics) OnRunEnd(run *RunInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight[run.Flow]--
+}
+
+func (h *histogram) observe(v float64) {
+	for i, le := range defaultDurationBuckets {
+		if v <= le {
+			h.counts[i]++
+			break
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+// WriteTo writes all metrics to w in the Prometheus text exposition format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	buf := &expositionBuffer{}
+
+	m.mu.Lock()
+	m.write(buf)
+	m.mu.Unlock()
+
+	n, err := buf.WriteTo(w)
+	if err != nil {
+		return n, fmt.Errorf("write metrics -> %w", err)
+	}
+	return n, nil
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = m.WriteTo(w)
+}
+
+var _ http.Handler = (*Metrics)(nil)
+
+func (m *Metrics) write(w *expositionBuffer) {
+	writeCounter(w, "gotaskflow_tasks_started_total", "Tasks picked up by a worker.", m.started)
+	writeCounter(w, "gotaskflow_tasks_finished_total", "Tasks that ran to completion.", m.finished)
+	writeCounter(w, "gotaskflow_tasks_failed_total", "Tasks that panicked.", m.failed)
+
+	w.header("gotaskflow_tasks_skipped_total", "Tasks that did not run, by reason.", "counter")
+	skipped := make([]skipLabels, 0, len(m.skipped))
+	for l := range m.skipped {
+		skipped = append(skipped, l)
+	}
+	sort.Slice(skipped, func(i, j int) bool {
+		if skipped[i].taskLabels != skipped[j].taskLabels {
+			return skipped[i].taskLabels.less(skipped[j].taskLabels)
+		}
+		return skipped[i].reason < skipped[j].reason
+	})
+	for _, l := range skipped {
+		w.sample("gotaskflow_tasks_skipped_total", l.pairs("reason", l.reason), float64(m.skipped[l]))
+	}
+
+	w.header("gotaskflow_task_duration_seconds", "Wall time of finished tasks.", "histogram")
+	for _, l := range sortedLabels(m.durations) {
+		h := m.durations[l]
+		var cumulative uint64
+		for i, le := range defaultDurationBuckets {
+			cumulative += h.counts[i]
+			w.sample("gotaskflow_task_duration_seconds_bucket", l.pairs("le", formatFloat(le)), float64(cumulative))
+		}
+		w.sample("gotaskflow_task_duration_seconds_bucket", l.pairs("le", "+Inf"), float64(h.count))
+		w.sample("gotaskflow_task_duration_seconds_sum", l.pairs(), h.sum)
+		w.sample("gotaskflow_task_duration_seconds_count", l.pairs(), float64(h.count))
+	}
+
+	w.header("gotaskflow_runs_in_flight", "TaskFlow runs currently executing.", "gauge")
+	flows := make([]string, 0, len(m.inFlight))
+	for flow := range m.inFlight {
+		flows = append(flows, flow)
+	}
+	sort.Strings(flows)
+	for _, flow := range flows {
+		w.sample("gotaskflow_runs_in_flight", []string{"flow", flow}, float64(m.inFlight[flow]))
+	}
+
+	var queued, running int
+	for _, e := range m.executors {
+		e.mu.Lock()
+		queued += e.wq.Len()
+		e.mu.Unlock()
+		running += e.pool.Running()
+	}
+	w.header("gotaskflow_queue_depth", "Ready tasks waiting to be dispatched to workers.", "gauge")
+	w.sample("gotaskflow_queue_depth", nil, float64(queued))
+	w.header("gotaskflow_active_workers", "Tasks submitted to the worker pool and not finished yet.", "gauge")
+	w.sample("gotaskflow_active_workers", nil, float64(running))
+}
+
+func writeCounter(w *expositionBuffer, name, help string, values map[taskLabels]uint64) {
+	w.header(name, help, "counter")
+	for _, l := range sortedLabels(values) {
+		w.sample(name, l.pairs(), float64(values[l]))
+	}
+}
+
+func sortedLabels[V any](m map[taskLabels]V) []taskLabels {
+	keys := make([]taskLabels, 0, len(m))
+	for l := range m {
+		keys = append(keys, l)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].less(keys[j]) })
+	return keys
+}
+
+func (l taskLabels) less(o taskLabels) bool {
+	if l.flow != o.flow {
+		return l.flow < o.flow
+	}
+	if l.task != o.task {
+		return l.task < o.task
+	}
+	if l.typ != o.typ {
+		return l.typ < o.typ
+	}
+	return l.labels < o.labels
+}
+
+// pairs returns the label name/value pairs of l followed by extra.
+func (l taskLabels) pairs(extra ...string) []string {
+	pairs := []string{"flow", l.flow, "task", l.task, "type", l.typ}
+	if l.labels != "" {
+		pairs = append(pairs, strings.Split(l.labels, "\x00")...)
+	}
+	return append(pairs, extra...)
+}
+
+// expositionBuffer formats metric families in the Prometheus text exposition format.
+type expositionBuffer struct {
+	bytes.Buffer
+}
+
+func (b *expositionBuffer) header(name, help, typ string) {
+	b.WriteString("# HELP " + name + " " + help + "\n# TYPE " + name + " " + typ + "\n")
+}
+
+// sample writes one sample; pairs alternates label names and values.
+func (b *expositionBuffer) sample(name string, pairs []string, v float64) {
+	b.WriteString(name)
+	if len(pairs) > 0 {
+		b.WriteByte('{')
+		for i := 0; i < len(pairs); i += 2 {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(pairs[i] + "=\"" + escapeLabelValue(pairs[i+1]) + "\"")
+		}
+		b.WriteByte('}')
+	}
+	b.WriteString(" " + formatFloat(v) + "\n")
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(v string) string {
+	return labelValueEscaper.Replace(v)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
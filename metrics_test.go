@@ -0,0 +1,103 @@
+package gotaskflow_test
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gotaskflow "github.com/noneback/go-taskflow"
+)
+
+func TestMetrics(t *testing.T) {
+	metrics := gotaskflow.NewMetrics()
+	executor := gotaskflow.NewExecutor(4, gotaskflow.WithMetrics(metrics))
+
+	tf := gotaskflow.NewTaskFlow("pipeline")
+	A := tf.NewTask("A", func() {})
+	tf.NewTask("labeled", func() {}).Label("owner", "infra").Label("cost-class", "low")
+	sub := tf.NewSubflow("sub", func(sf *gotaskflow.Subflow) {
+		sf.NewTask("S1", func() {})
+	})
+	cond := tf.NewCondition("cond", func() uint { return 0 })
+	B := tf.NewTask("B", func() { panic("B") })
+	A.Precede(sub)
+	sub.Precede(cond)
+	cond.Precede(B)
+
+	executor.Run(tf).Wait()
+
+	var buf bytes.Buffer
+	if _, err := metrics.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo error: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"# TYPE gotaskflow_tasks_started_total counter",
+		`gotaskflow_tasks_started_total{flow="pipeline",task="A",type="static"} 1`,
+		`gotaskflow_tasks_finished_total{flow="pipeline",task="sub",type="subflow"} 1`,
+		`gotaskflow_tasks_finished_total{flow="pipeline",task="S1",type="static"} 1`,
+		`gotaskflow_tasks_finished_total{flow="pipeline",task="cond",type="condition"} 1`,
+		`gotaskflow_tasks_failed_total{flow="pipeline",task="B",type="static"} 1`,
+		"# TYPE gotaskflow_task_duration_seconds histogram",
+		`gotaskflow_task_duration_seconds_bucket{flow="pipeline",task="A",type="static",le="+Inf"} 1`,
+		`gotaskflow_task_duration_seconds_count{flow="pipeline",task="A",type="static"} 1`,
+		`gotaskflow_tasks_finished_total{flow="pipeline",task="labeled",type="static",label_cost_class="low",label_owner="infra"} 1`,
+		`gotaskflow_runs_in_flight{flow="pipeline"} 0`,
+		"gotaskflow_queue_depth 0",
+		"# TYPE gotaskflow_active_workers gauge",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected metrics to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestMetricsLabelEscaping(t *testing.T) {
+	metrics := gotaskflow.NewMetrics()
+	tf := gotaskflow.NewTaskFlow(`say "hi"`)
+	tf.NewTask("a\\b\nc", func() {})
+	gotaskflow.NewExecutor(2, gotaskflow.WithMetrics(metrics)).Run(tf).Wait()
+
+	var buf bytes.Buffer
+	metrics.WriteTo(&buf)
+	want := `gotaskflow_tasks_finished_total{flow="say \"hi\"",task="a\\b\nc",type="static"} 1`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("expected escaped sample %q, got:\n%s", want, buf.String())
+	}
+}
+
+func TestMetricsLabelCollision(t *testing.T) {
+	metrics := gotaskflow.NewMetrics()
+	tf := gotaskflow.NewTaskFlow("G")
+	tf.NewTask("A", func() {}).Label("a-b", "1").Label("a_b", "2").Label("a.b", "3")
+	gotaskflow.NewExecutor(2, gotaskflow.WithMetrics(metrics)).Run(tf).Wait()
+
+	var buf bytes.Buffer
+	metrics.WriteTo(&buf)
+	want := `gotaskflow_tasks_finished_total{flow="G",task="A",type="static",label_a_b="1",label_a_b_2="3",label_a_b_3="2"} 1`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("expected sample %q, got:\n%s", want, buf.String())
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	metrics := gotaskflow.NewMetrics()
+	tf := gotaskflow.NewTaskFlow("G")
+	tf.NewTask("A", func() {})
+	gotaskflow.NewExecutor(2, gotaskflow.WithMetrics(metrics)).Run(tf).Wait()
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	resp := rec.Result()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), `gotaskflow_tasks_started_total{flow="G",task="A",type="static"} 1`) {
+		t.Errorf("unexpected body:\n%s", body)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"strconv"
 	"sync"
 	"sync/atomic"
-	"time"
 )
 
 const (
@@ -23,6 +22,7 @@ const (
 )
 
 type innerNode struct {
+	id          uint64
 	name        string
 	successors  []*innerNode
 	dependents  []*innerNode
@@ -33,6 +33,13 @@ type innerNode struct {
 	joinCounter atomic.Int32
 	g           *eGraph
 	priority    TaskPriority
+	snapshot    func() ([]byte, error) // captures task output for checkpoints
+	restore     func([]byte) error     // restores task output from checkpoints
+	cacheKey    func() (string, error) // set by Task.Cache
+	fn          string                 // registry entry the task was built from, if any
+	task        *Task                  // handle returned when the task was created
+	labels      map[string]string      // metadata set by Task.Label
+	semaphores  []*Semaphore           // held while the task runs, ordered by id
 }
 
 func (n *innerNode) recyclable() bool {
@@ -52,7 +59,7 @@ func (n *innerNode) setup() {
 	defer n.mu.Unlock()
 	n.state.Store(kNodeStateIdle)
 	for _, dep := range n.dependents {
-		if dep.Typ == nodeCondition {
+		if dep.Typ == nodeCondition || !n.g.run.selected(dep) {
 			continue
 		}
 
@@ -74,6 +81,18 @@ func (n *innerNode) precede(v *innerNode) {
 	v.dependents = append(v.dependents, n)
 }
 
+// remap numbers the branches of a condition after its successors, once they changed.
+func (n *innerNode) remap() {
+	cond, ok := n.ptr.(*Condition)
+	if !ok {
+		return
+	}
+	cond.mapper = make(map[uint]*innerNode, len(n.successors))
+	for i, succ := range n.successors {
+		cond.mapper[uint(i)] = succ
+	}
+}
+
 // hasCondPredecessor reports whether any predecessor of this node is a condition node.
 func (n *innerNode) hasCondPredecessor() bool {
 	for _, dep := range n.dependents {
@@ -84,11 +103,33 @@ func (n *innerNode) hasCondPredecessor() bool {
 	return false
 }
 
+// nodeIDs numbers the tasks created by the process.
+var nodeIDs atomic.Uint64
+
+// path returns the qualified name of n: the names of its flow and enclosing subflows followed by its own,
+// e.g. "flow/sub/task".
+func (n *innerNode) path() string {
+	if n.g == nil {
+		return n.name
+	}
+	return n.g.path() + "/" + n.name
+}
+
+// localPath returns the path of n below its flow, e.g. "sub/task".
+func (n *innerNode) localPath() string {
+	if n.g == nil || n.g.parent == nil {
+		return n.name
+	}
+	return n.g.parent.localPath() + "/" + n.name
+}
+
 func newNode(name string) *innerNode {
+	id := nodeIDs.Add(1)
 	if len(name) == 0 {
-		name = "N_" + strconv.Itoa(time.Now().Nanosecond())
+		name = "N_" + strconv.FormatUint(id, 10)
 	}
 	return &innerNode{
+		id:          id,
 		name:        name,
 		state:       atomic.Int32{},
 		successors:  make([]*innerNode, 0),
@@ -0,0 +1,217 @@
+package gotaskflow
+
+import (
+	"time"
+)
+
+// Observer receives task lifecycle events of an executor. Register it with WithObserver.
+// Callbacks are invoked from worker goroutines and must be safe for concurrent use.
+type Observer interface {
+	OnRunStart(run *RunInfo)                       // a TaskFlow run starts
+	OnTaskScheduled(task *TaskInfo)                // a task becomes ready and is queued
+	OnTaskStart(task *TaskInfo)                    // a worker picks the task up
+	OnTaskEnd(task *TaskInfo, outcome TaskOutcome) // the task ends, Cost is set
+	OnRunEnd(run *RunInfo)                         // the run ends, Cost, Canceled and Err are set
+}
+
+// NopObserver implements every Observer callback as a no-op. Embed it to implement only some of them.
+type NopObserver struct{}
+
+func (NopObserver) OnRunStart(*RunInfo)              {}
+func (NopObserver) OnTaskScheduled(*TaskInfo)        {}
+func (NopObserver) OnTaskStart(*TaskInfo)            {}
+func (NopObserver) OnTaskEnd(*TaskInfo, TaskOutcome) {}
+func (NopObserver) OnRunEnd(*RunInfo)                {}
+
+// RunInfo describes one run of a TaskFlow.
+type RunInfo struct {
+	Flow     string        // name of the TaskFlow
+	ID       string        // run ID passed to Resume, empty for Run
+	Begin    time.Time     // when the run started
+	Cost     time.Duration // wall time of the run, set when it ends
+	Canceled bool          // whether a panic canceled the run, set when it ends
+	Err      error         // why the run was canceled before running any task, e.g. a failed validation
+}
+
+// TaskInfo describes one execution of a task. The same TaskInfo is passed to OnTaskStart and OnTaskEnd.
+type TaskInfo struct {
+	Run        *RunInfo
+	ID         uint64 // ID of the task, see Task.ID
+	Name       string
+	Path       string            // qualified name of the task, e.g. "flow/sub/task"
+	Labels     map[string]string // set by Task.Label, must not be modified
+	Type       string            // "static", "subflow" or "condition"
+	Priority   TaskPriority
+	Parent     *TaskInfo     // execution of the enclosing subflow, nil for top-level tasks or when scheduled
+	Dependents []string      // names of predecessor tasks
+	Begin      time.Time     // when the task started, zero when scheduled
+	Cost       time.Duration // wall time of the task, set when it ends
+	Worker     int           // ID of the worker running the task, starting at 1; 0 when scheduled
+	Choice     uint          // branch taken by a condition task, set when it ends
+	// Iteration counts the executions of the task started before this one in the run, by Path, so that
+	// the tasks of a rebuilt subflow count along with it; 0 when scheduled.
+	Iteration int
+}
+
+// TaskOutcome tells how a task execution ended.
+type TaskOutcome int
+
+const (
+	TaskFinished TaskOutcome = iota // the task ran to completion
+	TaskFailed                      // the task panicked, canceling its graph
+	TaskCanceled                    // the task did not run since its graph was canceled
+	TaskRestored                    // the task did not run since a checkpoint already recorded it
+	TaskCached                      // the task did not run since its output was restored from cache
+)
+
+func (o TaskOutcome) String() string {
+	switch o {
+	case TaskFinished:
+		return "finished"
+	case TaskFailed:
+		return "failed"
+	case TaskCanceled:
+		return "canceled"
+	case TaskRestored:
+		return "restored"
+	case TaskCached:
+		return "cached"
+	default:
+		return "unknown"
+	}
+}
+
+// observer dispatches task lifecycle events to registered Observers and records spans.
+type observer struct {
+	profiler  *profiler
+	tracer    *tracer
+	observers []Observer
+	now       func() time.Time // time source of spans
+}
+
+func newObserver() *observer {
+	return &observer{now: time.Now}
+}
+
+func newTaskInfo(node *innerNode) *TaskInfo {
+	info := &TaskInfo{
+		ID:         node.id,
+		Name:       node.name,
+		Path:       node.path(),
+		Labels:     node.labels,
+		Type:       string(node.Typ),
+		Priority:   node.priority,
+		Dependents: getDependentNames(node),
+	}
+	if node.g != nil && node.g.run != nil {
+		info.Run = node.g.run.info
+	}
+	return info
+}
+
+// openSpan creates a span and reports the task start, if anyone observes.
+func (o *observer) openSpan(node *innerNode, parent *span, worker int) *span {
+	if len(o.observers) == 0 {
+		return nil
+	}
+	info := newTaskInfo(node)
+	info.Begin = o.now()
+	info.Worker = worker
+	if node.g != nil && node.g.run != nil {
+		info.Iteration = node.g.run.iteration(info.Path)
+	}
+	if parent != nil {
+		info.Parent = parent.info
+	}
+	s := &span{
+		extra:      attr{typ: node.Typ, name: node.name, path: info.Path},
+		begin:      info.Begin,
+		parent:     parent,
+		dependents: info.Dependents,
+		info:       info,
+	}
+	for _, obs := range o.observers {
+		obs.OnTaskStart(info)
+	}
+	return s
+}
+
+// chose records the branch taken by a condition task.
+func (o *observer) chose(s *span, choice uint) {
+	if s != nil {
+		s.info.Choice = choice
+	}
+}
+
+// endSpan ends the span and reports the task end.
+func (o *observer) endSpan(s *span, outcome TaskOutcome) {
+	if s == nil {
+		return
+	}
+	s.cost = o.now().Sub(s.begin)
+	s.outcome = outcome
+	s.info.Cost = s.cost
+	for _, obs := range o.observers {
+		obs.OnTaskEnd(s.info, outcome)
+	}
+}
+
+func (o *observer) scheduled(node *innerNode) {
+	if len(o.observers) == 0 {
+		return
+	}
+	info := newTaskInfo(node)
+	for _, obs := range o.observers {
+		obs.OnTaskScheduled(info)
+	}
+}
+
+func (o *observer) runStart(run *RunInfo) {
+	for _, obs := range o.observers {
+		obs.OnRunStart(run)
+	}
+}
+
+func (o *observer) runEnd(run *RunInfo) {
+	for _, obs := range o.observers {
+		obs.OnRunEnd(run)
+	}
+}
+
+// runRejected reports a run that err kept from running any task, as a canceled run.
+func (o *observer) runRejected(run *RunInfo, err error) {
+	run.Begin = o.now()
+	run.Canceled, run.Err = true, err
+	o.runStart(run)
+	o.runEnd(run)
+}
+
+func (o *observer) withObserver(obs Observer) {
+	o.observers = append(o.observers, obs)
+}
+
+func (o *observer) withProfiler(p *profiler) {
+	o.profiler = p
+	o.withObserver(p)
+}
+
+func (o *observer) withTracer(t *tracer) {
+	o.tracer = t
+	o.withObserver(t)
+}
+
+// spanOf rebuilds the span chain of a task execution reported to an Observer.
+func spanOf(info *TaskInfo, outcome TaskOutcome) *span {
+	s := &span{
+		extra:      attr{typ: nodeType(info.Type), name: info.Name, path: info.Path, cached: outcome == TaskCached},
+		begin:      info.Begin,
+		cost:       info.Cost,
+		dependents: info.Dependents,
+		outcome:    outcome,
+		info:       info,
+	}
+	if info.Parent != nil {
+		s.parent = spanOf(info.Parent, TaskFinished)
+	}
+	return s
+}
@@ -3,6 +3,7 @@ package gotaskflow
 import (
 	"bytes"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -18,7 +19,7 @@ func TestObserverOpenSpan(t *testing.T) {
 		dependents: []*innerNode{{name: "dep1"}, {name: "dep2"}},
 	}
 
-	s := obs.openSpan(node, nil)
+	s := obs.openSpan(node, nil, 1)
 	if s == nil {
 		t.Fatal("expected non-nil span")
 	}
@@ -45,14 +46,14 @@ func TestObserverOpenSpanNil(t *testing.T) {
 		Typ:  nodeStatic,
 	}
 
-	s := obs.openSpan(node, nil)
+	s := obs.openSpan(node, nil, 1)
 	if s != nil {
 		t.Errorf("expected nil span when no profiler/tracer, got %v", s)
 	}
 }
 
-// TestObserverCloseSpan tests that closeSpan records the span to profiler.
-func TestObserverCloseSpan(t *testing.T) {
+// TestObserverEndSpan tests that endSpan records the span to profiler.
+func TestObserverEndSpan(t *testing.T) {
 	obs := newObserver()
 	p := newProfiler()
 	obs.withProfiler(p)
@@ -62,9 +63,9 @@ func TestObserverCloseSpan(t *testing.T) {
 		Typ:  nodeStatic,
 	}
 
-	s := obs.openSpan(node, nil)
+	s := obs.openSpan(node, nil, 1)
 	time.Sleep(1 * time.Millisecond) // ensure some duration
-	obs.closeSpan(s, true)
+	obs.endSpan(s, TaskFinished)
 
 	if len(p.spans) != 1 {
 		t.Errorf("expected 1 span in profiler, got %d", len(p.spans))
@@ -74,8 +75,8 @@ func TestObserverCloseSpan(t *testing.T) {
 	}
 }
 
-// TestObserverCloseSpanNotOk tests that closeSpan does not record span when ok=false (panic).
-func TestObserverCloseSpanNotOk(t *testing.T) {
+// TestObserverEndSpanFailed tests that endSpan does not record the span of a panicked task.
+func TestObserverEndSpanFailed(t *testing.T) {
 	obs := newObserver()
 	p := newProfiler()
 	obs.withProfiler(p)
@@ -85,22 +86,22 @@ func TestObserverCloseSpanNotOk(t *testing.T) {
 		Typ:  nodeStatic,
 	}
 
-	s := obs.openSpan(node, nil)
+	s := obs.openSpan(node, nil, 1)
 	time.Sleep(1 * time.Millisecond)
-	obs.closeSpan(s, false) // ok=false means panic occurred
+	obs.endSpan(s, TaskFailed)
 
 	if len(p.spans) != 0 {
 		t.Errorf("expected 0 spans in profiler (panicked task), got %d", len(p.spans))
 	}
 }
 
-// TestObserverCloseSpanNil tests that closeSpan handles nil span gracefully.
-func TestObserverCloseSpanNil(t *testing.T) {
+// TestObserverEndSpanNil tests that endSpan handles nil span gracefully.
+func TestObserverEndSpanNil(t *testing.T) {
 	obs := newObserver()
 	obs.withProfiler(newProfiler())
 
 	// Should not panic
-	obs.closeSpan(nil, true)
+	obs.endSpan(nil, TaskFinished)
 }
 
 // TestObserverWithProfiler tests that withProfiler sets the profiler correctly.
@@ -139,8 +140,8 @@ func TestObserverSpanParentChain(t *testing.T) {
 	parentNode := &innerNode{name: "parent", Typ: nodeStatic}
 	childNode := &innerNode{name: "child", Typ: nodeStatic}
 
-	parentSpan := obs.openSpan(parentNode, nil)
-	childSpan := obs.openSpan(childNode, parentSpan)
+	parentSpan := obs.openSpan(parentNode, nil, 1)
+	childSpan := obs.openSpan(childNode, parentSpan, 1)
 
 	if childSpan.parent != parentSpan {
 		t.Error("child span should have parent span set")
@@ -159,9 +160,9 @@ func TestObserverWithBothProfilerAndTracer(t *testing.T) {
 	obs.withTracer(tr)
 
 	node := &innerNode{name: "test-task", Typ: nodeStatic}
-	s := obs.openSpan(node, nil)
+	s := obs.openSpan(node, nil, 1)
 	time.Sleep(1 * time.Millisecond)
-	obs.closeSpan(s, true)
+	obs.endSpan(s, TaskFinished)
 
 	if len(p.spans) != 1 {
 		t.Errorf("expected 1 span in profiler, got %d", len(p.spans))
@@ -190,9 +191,9 @@ func TestObserverConcurrent(t *testing.T) {
 				name: "task",
 				Typ:  nodeStatic,
 			}
-			s := obs.openSpan(node, nil)
+			s := obs.openSpan(node, nil, 1)
 			time.Sleep(time.Microsecond)
-			obs.closeSpan(s, true)
+			obs.endSpan(s, TaskFinished)
 		}(i)
 	}
 	wg.Wait()
@@ -267,4 +268,135 @@ func TestObserverIntegrationWithBoth(t *testing.T) {
 	if traceBuf.Len() == 0 {
 		t.Error("expected trace output")
 	}
-}
\ No newline at end of file
+}
+
+// recordingObserver records lifecycle events for assertions.
+type recordingObserver struct {
+	mu        sync.Mutex
+	runs      []string
+	scheduled map[string]int
+	started   map[string]int
+	outcomes  map[string]TaskOutcome
+	parents   map[string]string
+}
+
+func newRecordingObserver() *recordingObserver {
+	return &recordingObserver{
+		scheduled: make(map[string]int),
+		started:   make(map[string]int),
+		outcomes:  make(map[string]TaskOutcome),
+		parents:   make(map[string]string),
+	}
+}
+
+func (o *recordingObserver) OnRunStart(run *RunInfo) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.runs = append(o.runs, "start:"+run.Flow)
+}
+
+func (o *recordingObserver) OnTaskScheduled(task *TaskInfo) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.scheduled[task.Name]++
+}
+
+func (o *recordingObserver) OnTaskStart(task *TaskInfo) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.started[task.Name]++
+	if task.Parent != nil {
+		o.parents[task.Name] = task.Parent.Name
+	}
+}
+
+func (o *recordingObserver) OnTaskEnd(task *TaskInfo, outcome TaskOutcome) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.outcomes[task.Name] = outcome
+}
+
+func (o *recordingObserver) OnRunEnd(run *RunInfo) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.runs = append(o.runs, "end:"+run.Flow)
+	if run.Canceled {
+		o.runs = append(o.runs, "canceled")
+	}
+}
+
+// TestObserverPublicInterface tests that a user Observer receives the whole task lifecycle.
+func TestObserverPublicInterface(t *testing.T) {
+	obs := newRecordingObserver()
+	tf := NewTaskFlow("flow")
+	a := tf.NewTask("A", func() {})
+	sub := tf.NewSubflow("sub", func(sf *Subflow) {
+		sf.NewTask("S1", func() {})
+	})
+	cond := tf.NewCondition("cond", func() uint { return 0 })
+	b := tf.NewTask("B", func() {})
+	a.Precede(sub)
+	sub.Precede(cond)
+	cond.Precede(b)
+
+	NewExecutor(4, WithObserver(obs)).Run(tf).Wait()
+
+	if len(obs.runs) != 2 || obs.runs[0] != "start:flow" || obs.runs[1] != "end:flow" {
+		t.Errorf("unexpected run events: %v", obs.runs)
+	}
+	for _, name := range []string{"A", "sub", "S1", "cond", "B"} {
+		if obs.scheduled[name] != 1 || obs.started[name] != 1 {
+			t.Errorf("task %s: scheduled %d, started %d", name, obs.scheduled[name], obs.started[name])
+		}
+		if obs.outcomes[name] != TaskFinished {
+			t.Errorf("task %s: expected finished, got %v", name, obs.outcomes[name])
+		}
+	}
+	if obs.parents["S1"] != "sub" {
+		t.Errorf("expected S1 parent sub, got %q", obs.parents["S1"])
+	}
+}
+
+// TestObserverOutcomes tests that failed and canceled tasks are reported as such.
+func TestObserverOutcomes(t *testing.T) {
+	obs := newRecordingObserver()
+	tf := NewTaskFlow("flow")
+	a := tf.NewTask("A", func() { panic("A") })
+	b := tf.NewTask("B", func() {})
+	a.Precede(b)
+
+	NewExecutor(1, WithObserver(obs)).Run(tf).Wait()
+
+	if obs.outcomes["A"] != TaskFailed {
+		t.Errorf("expected A failed, got %v", obs.outcomes["A"])
+	}
+	if _, ok := obs.outcomes["B"]; ok && obs.outcomes["B"] != TaskCanceled {
+		t.Errorf("expected B canceled or never started, got %v", obs.outcomes["B"])
+	}
+	if !containsStr(obs.runs, "canceled") {
+		t.Errorf("expected canceled run, got %v", obs.runs)
+	}
+}
+
+// TestNopObserver tests that NopObserver can be embedded to observe a single event.
+func TestNopObserver(t *testing.T) {
+	var ended atomicCounter
+	tf := NewTaskFlow("flow")
+	tf.NewTask("A", func() {})
+	tf.NewTask("B", func() {})
+
+	NewExecutor(4, WithObserver(&ended)).Run(tf).Wait()
+
+	if ended.n.Load() != 2 {
+		t.Errorf("expected 2 ended tasks, got %d", ended.n.Load())
+	}
+}
+
+type atomicCounter struct {
+	NopObserver
+	n atomic.Int32
+}
+
+func (c *atomicCounter) OnTaskEnd(*TaskInfo, TaskOutcome) {
+	c.n.Add(1)
+}
@@ -17,3 +17,50 @@ func WithTracer() Option {
 		e.obs.withTracer(newTracer())
 	}
 }
+
+// WithObserver registers observers notified of run and task lifecycle events.
+func WithObserver(obs ...Observer) Option {
+	return func(e *innerExecutorImpl) {
+		for _, o := range obs {
+			e.obs.withObserver(o)
+		}
+	}
+}
+
+// WithMetrics collects executor metrics into m, see Metrics.
+func WithMetrics(m *Metrics) Option {
+	return func(e *innerExecutorImpl) {
+		m.bind(e)
+		e.obs.withObserver(m)
+	}
+}
+
+// WithCheckpoint enables Executor.Resume, recording task completions of resumable runs into store.
+func WithCheckpoint(store CheckpointStore) Option {
+	return func(e *innerExecutorImpl) {
+		e.ckpt = store
+	}
+}
+
+// WithCache skips tasks declaring a cache key, see Task.Cache, whose output cache holds.
+func WithCache(cache TaskCache) Option {
+	return func(e *innerExecutorImpl) {
+		e.cache = cache
+	}
+}
+
+// WithSubflowRebuild instantiates every subflow again on each execution, as Task.Rebuild does for one.
+func WithSubflowRebuild() Option {
+	return func(e *innerExecutorImpl) {
+		e.rebuild = true
+	}
+}
+
+// WithValidation validates every TaskFlow with TaskFlow.Validate before running it, and every subflow
+// once instantiated. An invalid TaskFlow is not run: the error is logged and observers see a canceled
+// run with RunInfo.Err set, Runtime.CoRun returns it. An invalid subflow fails like a panicking task.
+func WithValidation() Option {
+	return func(e *innerExecutorImpl) {
+		e.validate = true
+	}
+}
@@ -0,0 +1,335 @@
+package gotaskflow
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpanExporter receives the OpenTelemetry spans of every finished run.
+type SpanExporter interface {
+	ExportSpans(spans []OTelSpan) error
+}
+
+// OTelSpan is an OpenTelemetry span of a run or of one task execution.
+type OTelSpan struct {
+	TraceID      string // 32 lowercase hex digits
+	SpanID       string // 16 lowercase hex digits
+	ParentSpanID string // empty for a root span
+	Name         string
+	Start, End   time.Time
+	Attributes   map[string]string
+	Links        []OTelLink // spans of the predecessor tasks
+	Failed       bool       // status ERROR, the task panicked or the run was canceled
+}
+
+// OTelLink references another span.
+type OTelLink struct {
+	TraceID string
+	SpanID  string
+}
+
+// OTelExporter maps runs and task executions to OpenTelemetry spans: each run becomes a root span,
+// each task a child of its enclosing subflow span or of the run span, linked to its predecessors.
+// Register it with WithObserver; spans of a run are handed to the SpanExporter when the run ends.
+type OTelExporter struct {
+	NopObserver
+	exp    SpanExporter
+	mu     *sync.Mutex
+	remote *OTelLink // incoming trace context of subsequent runs
+	runs   map[*RunInfo]*otelRun
+	err    error
+}
+
+type otelRun struct {
+	root  OTelSpan
+	spans []OTelSpan
+	ids   map[*TaskInfo]string
+	last  map[otelScope]string // span of the latest execution of a task
+}
+
+// otelScope identifies a task by name within its enclosing subflow execution.
+type otelScope struct {
+	parent *TaskInfo
+	name   string
+}
+
+// NewOTelExporter returns an OTelExporter handing spans to exp.
+func NewOTelExporter(exp SpanExporter) *OTelExporter {
+	return &OTelExporter{
+		exp:  exp,
+		mu:   &sync.Mutex{},
+		runs: make(map[*RunInfo]*otelRun),
+	}
+}
+
+// SetTraceParent makes subsequent runs children of the W3C traceparent header value,
+// e.g. "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01". An empty value starts new traces.
+func (o *OTelExporter) SetTraceParent(traceparent string) error {
+	var remote *OTelLink
+	if traceparent != "" {
+		parts := strings.Split(traceparent, "-")
+		if len(parts) != 4 || len(parts[0]) != 2 || !isHexID(parts[1], 32) || !isHexID(parts[2], 16) {
+			return fmt.Errorf("invalid traceparent %q", traceparent)
+		}
+		remote = &OTelLink{TraceID: parts[1], SpanID: parts[2]}
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.remote = remote
+	return nil
+}
+
+// Err returns the last error returned by the SpanExporter, if any.
+func (o *OTelExporter) Err() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.err
+}
+
+func isHexID(s string, n int) bool {
+	if len(s) != n || strings.Trim(s, "0") == "" {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil && strings.ToLower(s) == s
+}
+
+func newOTelID(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("generate span id -> %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// OnRunStart implements Observer.
+func (o *OTelExporter) OnRunStart(run *RunInfo) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	root := OTelSpan{
+		TraceID: newOTelID(16),
+		SpanID:  newOTelID(8),
+		Name:    run.Flow,
+		Start:   run.Begin,
+		Attributes: map[string]string{
+			"taskflow.flow": run.Flow,
+		},
+	}
+	if run.ID != "" {
+		root.Attributes["taskflow.run.id"] = run.ID
+	}
+	if o.remote != nil {
+		root.TraceID = o.remote.TraceID
+		root.ParentSpanID = o.remote.SpanID
+	}
+	o.runs[run] = &otelRun{
+		root: root,
+		ids:  make(map[*TaskInfo]string),
+		last: make(map[otelScope]string),
+	}
+}
+
+// OnTaskStart implements Observer.
+func (o *OTelExporter) OnTaskStart(info *TaskInfo) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if r, ok := o.runs[info.Run]; ok {
+		r.ids[info] = newOTelID(8)
+	}
+}
+
+// OnTaskEnd implements Observer.
+func (o *OTelExporter) OnTaskEnd(info *TaskInfo, outcome TaskOutcome) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	r, ok := o.runs[info.Run]
+	if !ok {
+		return
+	}
+	s := OTelSpan{
+		TraceID:      r.root.TraceID,
+		SpanID:       r.ids[info],
+		ParentSpanID: r.root.SpanID,
+		Name:         info.Name,
+		Start:        info.Begin,
+		End:          info.Begin.Add(info.Cost),
+		Attributes: map[string]string{
+			"taskflow.flow":           r.root.Name,
+			"taskflow.task.path":      info.Path,
+			"taskflow.task.iteration": strconv.Itoa(info.Iteration),
+			"taskflow.task.type":      info.Type,
+			"taskflow.task.priority":  strconv.Itoa(int(info.Priority)),
+			"taskflow.outcome":        outcome.String(),
+		},
+		Failed: outcome == TaskFailed,
+	}
+	for k, v := range info.Labels {
+		s.Attributes["taskflow.label."+k] = v
+	}
+	if info.Parent != nil {
+		s.ParentSpanID = r.ids[info.Parent]
+		s.Attributes["taskflow.parent"] = info.Parent.Name
+	}
+	for _, dep := range info.Dependents {
+		if id, ok := r.last[otelScope{parent: info.Parent, name: dep}]; ok {
+			s.Links = append(s.Links, OTelLink{TraceID: r.root.TraceID, SpanID: id})
+		}
+	}
+	r.last[otelScope{parent: info.Parent, name: info.Name}] = s.SpanID
+	r.spans = append(r.spans, s)
+}
+
+// OnRunEnd implements Observer.
+func (o *OTelExporter) OnRunEnd(run *RunInfo) {
+	o.mu.Lock()
+	r, ok := o.runs[run]
+	delete(o.runs, run)
+	o.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	r.root.End = run.Begin.Add(run.Cost)
+	r.root.Failed = run.Canceled
+	spans := append([]OTelSpan{r.root}, r.spans...)
+	if err := o.exp.ExportSpans(spans); err != nil {
+		o.mu.Lock()
+		o.err = err
+		o.mu.Unlock()
+	}
+}
+
+// otlpJSONExporter writes spans as OTLP/JSON, one ExportTraceServiceRequest per line.
+type otlpJSONExporter struct {
+	w  io.Writer
+	mu *sync.Mutex
+}
+
+// NewOTLPJSONExporter returns a SpanExporter writing spans to w in the OTLP/JSON encoding,
+// one ExportTraceServiceRequest per line, as the OpenTelemetry Collector file exporter does.
+func NewOTLPJSONExporter(w io.Writer) SpanExporter {
+	return &otlpJSONExporter{w: w, mu: &sync.Mutex{}}
+}
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Links             []otlpLink     `json:"links,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpLink struct {
+	TraceID string `json:"traceId"`
+	SpanID  string `json:"spanId"`
+}
+
+type otlpStatus struct {
+	Code int `json:"code,omitempty"` // 1 OK, 2 ERROR
+}
+
+const (
+	otlpSpanKindInternal = 1
+	otlpStatusOK         = 1
+	otlpStatusError      = 2
+)
+
+func otlpAttributes(attrs map[string]string) []otlpKeyValue {
+	kvs := make([]otlpKeyValue, 0, len(attrs))
+	for k, v := range attrs {
+		kvs = append(kvs, otlpKeyValue{Key: k, Value: otlpAnyValue{StringValue: v}})
+	}
+	sort.Slice(kvs, func(i, j int) bool { return kvs[i].Key < kvs[j].Key })
+	return kvs
+}
+
+// ExportSpans implements SpanExporter.
+func (e *otlpJSONExporter) ExportSpans(spans []OTelSpan) error {
+	out := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		o := otlpSpan{
+			TraceID:           s.TraceID,
+			SpanID:            s.SpanID,
+			ParentSpanID:      s.ParentSpanID,
+			Name:              s.Name,
+			Kind:              otlpSpanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(s.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.End.UnixNano(), 10),
+			Attributes:        otlpAttributes(s.Attributes),
+			Status:            otlpStatus{Code: otlpStatusOK},
+		}
+		if s.Failed {
+			o.Status.Code = otlpStatusError
+		}
+		for _, l := range s.Links {
+			o.Links = append(o.Links, otlpLink{TraceID: l.TraceID, SpanID: l.SpanID})
+		}
+		out = append(out, o)
+	}
+
+	req := otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: otlpAttributes(map[string]string{"service.name": "go-taskflow"})},
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: "github.com/noneback/go-taskflow"},
+			Spans: out,
+		}},
+	}}}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("encode otlp -> %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, err := e.w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write otlp -> %w", err)
+	}
+	return nil
+}
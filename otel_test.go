@@ -0,0 +1,157 @@
+package gotaskflow_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	gotaskflow "github.com/noneback/go-taskflow"
+)
+
+type otlpDump struct {
+	ResourceSpans []struct {
+		ScopeSpans []struct {
+			Scope struct {
+				Name string `json:"name"`
+			} `json:"scope"`
+			Spans []struct {
+				TraceID           string `json:"traceId"`
+				SpanID            string `json:"spanId"`
+				ParentSpanID      string `json:"parentSpanId"`
+				Name              string `json:"name"`
+				Kind              int    `json:"kind"`
+				StartTimeUnixNano string `json:"startTimeUnixNano"`
+				EndTimeUnixNano   string `json:"endTimeUnixNano"`
+				Links             []struct {
+					SpanID string `json:"spanId"`
+				} `json:"links"`
+				Status struct {
+					Code int `json:"code"`
+				} `json:"status"`
+			} `json:"spans"`
+		} `json:"scopeSpans"`
+	} `json:"resourceSpans"`
+}
+
+func TestOTelExportToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spans.json")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exporter := gotaskflow.NewOTelExporter(gotaskflow.NewOTLPJSONExporter(f))
+	if err := exporter.SetTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"); err != nil {
+		t.Fatal(err)
+	}
+
+	tf := gotaskflow.NewTaskFlow("G")
+	A := tf.NewTask("A", func() {})
+	S := tf.NewSubflow("S", func(sf *gotaskflow.Subflow) {
+		sf.NewTask("S1", func() {})
+	})
+	B := tf.NewTask("B", func() { panic("B") })
+	A.Precede(S)
+	S.Precede(B)
+	gotaskflow.NewExecutor(4, gotaskflow.WithObserver(exporter)).Run(tf).Wait()
+	f.Close()
+
+	if err := exporter.Err(); err != nil {
+		t.Fatalf("export error: %v", err)
+	}
+
+	data, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer data.Close()
+	scanner := bufio.NewScanner(data)
+	if !scanner.Scan() {
+		t.Fatal("expected one OTLP request")
+	}
+	var dump otlpDump
+	if err := json.Unmarshal(scanner.Bytes(), &dump); err != nil {
+		t.Fatalf("invalid OTLP/JSON: %v", err)
+	}
+	if scanner.Scan() {
+		t.Fatal("expected exactly one OTLP request")
+	}
+
+	spans := dump.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 5 {
+		t.Fatalf("expected 5 spans (run, A, S, S1, B), got %d", len(spans))
+	}
+	byName := map[string]int{}
+	for i, s := range spans {
+		byName[s.Name] = i
+		if s.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+			t.Errorf("span %s: expected incoming trace id, got %s", s.Name, s.TraceID)
+		}
+		if len(s.SpanID) != 16 || s.Kind != 1 || s.StartTimeUnixNano == "" || s.EndTimeUnixNano == "" {
+			t.Errorf("malformed span %+v", s)
+		}
+	}
+
+	root, a, sub, s1, b := spans[byName["G"]], spans[byName["A"]], spans[byName["S"]], spans[byName["S1"]], spans[byName["B"]]
+	if root.ParentSpanID != "00f067aa0ba902b7" {
+		t.Errorf("run span should be a child of the incoming span, got parent %q", root.ParentSpanID)
+	}
+	if a.ParentSpanID != root.SpanID || sub.ParentSpanID != root.SpanID || b.ParentSpanID != root.SpanID {
+		t.Error("top-level task spans should be children of the run span")
+	}
+	if s1.ParentSpanID != sub.SpanID {
+		t.Errorf("S1 should be a child of subflow S, got parent %q", s1.ParentSpanID)
+	}
+	if len(sub.Links) != 1 || sub.Links[0].SpanID != a.SpanID {
+		t.Errorf("S should link to its predecessor A, got %+v", sub.Links)
+	}
+	if b.Status.Code != 2 || a.Status.Code != 1 {
+		t.Errorf("unexpected status codes: A=%d B=%d", a.Status.Code, b.Status.Code)
+	}
+}
+
+type recordingExporter struct {
+	spans [][]gotaskflow.OTelSpan
+	err   error
+}
+
+func (r *recordingExporter) ExportSpans(spans []gotaskflow.OTelSpan) error {
+	r.spans = append(r.spans, spans)
+	return r.err
+}
+
+func TestOTelCustomExporter(t *testing.T) {
+	rec := &recordingExporter{err: errors.New("unavailable")}
+	exporter := gotaskflow.NewOTelExporter(rec)
+	executor := gotaskflow.NewExecutor(2, gotaskflow.WithObserver(exporter))
+
+	tf := gotaskflow.NewTaskFlow("G")
+	tf.NewTask("A", func() {})
+	executor.Run(tf).Wait()
+	executor.Run(tf).Wait()
+
+	if len(rec.spans) != 2 {
+		t.Fatalf("expected one export per run, got %d", len(rec.spans))
+	}
+	if rec.spans[0][0].TraceID == rec.spans[1][0].TraceID {
+		t.Error("runs without trace context should start new traces")
+	}
+	if !errors.Is(exporter.Err(), rec.err) {
+		t.Errorf("expected exporter error to be kept, got %v", exporter.Err())
+	}
+}
+
+func TestOTelInvalidTraceParent(t *testing.T) {
+	exporter := gotaskflow.NewOTelExporter(&recordingExporter{})
+	for _, tp := range []string{
+		"garbage",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00F067AA0BA902B7-01",
+	} {
+		if err := exporter.SetTraceParent(tp); err == nil {
+			t.Errorf("expected error for %q", tp)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package gotaskflow
+
+import (
+	"fmt"
+	"slices"
+)
+
+// RunTargets runs the tasks named targets and their transitive predecessors only, like make does.
+// A target inside a subflow runs its whole top-level subflow; a condition taking a branch left out
+// of the selection schedules nothing. It panics if a target is not found by TaskFlow.FindTask.
+func (e *innerExecutorImpl) RunTargets(tf *TaskFlow, targets ...string) Executor {
+	rs := newRunState(tf, "")
+	rs.only = selectTargets(tf, targets)
+	e.run(tf, rs)
+	return e
+}
+
+// RunFrom runs task and its transitive successors only, assuming its predecessors already succeeded.
+// A task inside a subflow runs from its top-level subflow. A task inside a condition loop starts the
+// run even though the loop leads back to it.
+func (e *innerExecutorImpl) RunFrom(tf *TaskFlow, task *Task) Executor {
+	rs := newRunState(tf, "")
+	rs.only, rs.from = selectFrom(tf, task)
+	e.run(tf, rs)
+	return e
+}
+
+// entry tells whether node starts the run: the task RunFrom runs from does, even if a condition loops
+// back to it, other selected tasks do once they have no selected predecessor.
+func (r *runState) entry(node *innerNode) bool {
+	if r != nil && r.from == node {
+		return true
+	}
+	return r.selected(node) && !slices.ContainsFunc(node.dependents, r.selected)
+}
+
+// selected tells whether node is part of the run. Tasks of subflows always are, with their subflow.
+func (r *runState) selected(node *innerNode) bool {
+	return r == nil || r.only == nil || node.g.parent != nil || r.only[node]
+}
+
+func selectTargets(tf *TaskFlow, targets []string) map[*innerNode]bool {
+	only := make(map[*innerNode]bool)
+	for _, name := range targets {
+		task := tf.FindTask(name)
+		if task == nil {
+			panic(fmt.Sprintf("target %q not found in taskflow %q", name, tf.Name()))
+		}
+		closure(topLevel(tf, task), only, func(n *innerNode) []*innerNode { return n.dependents })
+	}
+	return only
+}
+
+// selectFrom returns the tasks run from task, and the top-level task they are run from.
+func selectFrom(tf *TaskFlow, task *Task) (only map[*innerNode]bool, from *innerNode) {
+	only, from = make(map[*innerNode]bool), topLevel(tf, task)
+	closure(from, only, func(n *innerNode) []*innerNode { return n.successors })
+	return only, from
+}
+
+// topLevel returns the task of tf holding task, task itself if it is not inside a subflow.
+func topLevel(tf *TaskFlow, task *Task) *innerNode {
+	n := task.node
+	for n.g != nil && n.g != tf.graph && n.g.parent != nil {
+		n = n.g.parent
+	}
+	if n.g != tf.graph {
+		panic(fmt.Sprintf("task %q is not in taskflow %q", task.Name(), tf.Name()))
+	}
+	return n
+}
+
+// closure adds n and the nodes of its graph reachable through next to nodes.
+func closure(n *innerNode, nodes map[*innerNode]bool, next func(*innerNode) []*innerNode) {
+	if nodes[n] {
+		return
+	}
+	nodes[n] = true
+	for _, m := range next(n) {
+		if m.g == n.g {
+			closure(m, nodes, next)
+		}
+	}
+}
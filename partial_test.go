@@ -0,0 +1,140 @@
+package gotaskflow_test
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	gotaskflow "github.com/noneback/go-taskflow"
+)
+
+// newCIFlow returns checkout -> build -> {test -> deploy, docs}, and lint, recording the tasks run.
+func newCIFlow() (*gotaskflow.TaskFlow, func() string) {
+	var (
+		mu  sync.Mutex
+		ran []string
+	)
+	task := func(name string) func() {
+		return func() {
+			mu.Lock()
+			defer mu.Unlock()
+			ran = append(ran, name)
+		}
+	}
+	tf := gotaskflow.NewTaskFlow("ci")
+	checkout := tf.NewTask("checkout", task("checkout"))
+	build := tf.NewTask("build", task("build"))
+	test := tf.NewSubflow("test", func(sf *gotaskflow.Subflow) {
+		sf.NewTask("unit", task("unit"))
+		sf.NewTask("e2e", task("e2e"))
+	})
+	deploy := tf.NewTask("deploy", task("deploy"))
+	docs := tf.NewTask("docs", task("docs"))
+	tf.NewTask("lint", task("lint"))
+	checkout.Precede(build)
+	build.Precede(test, docs)
+	test.Precede(deploy)
+
+	return tf, func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		got := slices.Clone(ran)
+		slices.Sort(got)
+		ran = ran[:0]
+		return strings.Join(got, ",")
+	}
+}
+
+func TestRunTargets(t *testing.T) {
+	tf, ran := newCIFlow()
+	executor := gotaskflow.NewExecutor(4)
+
+	executor.RunTargets(tf, "docs", "lint").Wait()
+	if got := ran(); got != "build,checkout,docs,lint" {
+		t.Errorf("unexpected tasks run for docs and lint: %s", got)
+	}
+	executor.RunTargets(tf, "deploy").Wait()
+	if got := ran(); got != "build,checkout,deploy,e2e,unit" {
+		t.Errorf("unexpected tasks run for deploy: %s", got)
+	}
+	// the test subflow is instantiated now, its tasks run with it
+	executor.RunTargets(tf, "unit").Wait()
+	if got := ran(); got != "build,checkout,e2e,unit" {
+		t.Errorf("unexpected tasks run for unit: %s", got)
+	}
+	executor.Run(tf).Wait()
+	if got := ran(); got != "build,checkout,deploy,docs,e2e,lint,unit" {
+		t.Errorf("expected a full run after partial ones, got %s", got)
+	}
+
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), `target "missing" not found`) {
+			t.Errorf("expected a panic for an unknown target, got %v", r)
+		}
+	}()
+	executor.RunTargets(tf, "missing")
+}
+
+func TestRunFrom(t *testing.T) {
+	tf, ran := newCIFlow()
+	gotaskflow.NewExecutor(4).RunFrom(tf, tf.FindTask("test")).Wait()
+	if got := ran(); got != "deploy,e2e,unit" {
+		t.Errorf("unexpected tasks run from test: %s", got)
+	}
+
+	sim := gotaskflow.NewSimulator(1)
+	sim.RunFrom(tf, tf.FindTask("build"))
+	if got := ran(); got != "build,deploy,docs,e2e,unit" {
+		t.Errorf("unexpected tasks run from build: %s", got)
+	}
+}
+
+func TestRunTargetsCondition(t *testing.T) {
+	var ran []string
+	tf := gotaskflow.NewTaskFlow("G")
+	cond := tf.NewCondition("cond", func() uint { return 0 })
+	A := tf.NewTask("A", func() { ran = append(ran, "A") })
+	B := tf.NewTask("B", func() { ran = append(ran, "B") })
+	cond.Precede(A, B)
+
+	gotaskflow.NewSimulator(1).RunTargets(tf, "B")
+	if len(ran) != 0 {
+		t.Errorf("expected no task run since cond took a branch not selected, got %v", ran)
+	}
+	gotaskflow.NewSimulator(1).RunFrom(tf, B)
+	if !slices.Equal(ran, []string{"B"}) {
+		t.Errorf("expected B to run from a condition branch, got %v", ran)
+	}
+}
+
+func TestRunFromLoop(t *testing.T) {
+	var (
+		xs   int
+		done bool
+	)
+	newFlow := func() (*gotaskflow.TaskFlow, *gotaskflow.Task) {
+		xs, done = 0, false
+		tf := gotaskflow.NewTaskFlow("G")
+		init := tf.NewTask("init", func() {})
+		X := tf.NewTask("X", func() { xs++ })
+		c := tf.NewCondition("c", func() uint {
+			if xs < 3 {
+				return 0
+			}
+			return 1
+		})
+		init.Precede(X)
+		X.Precede(c)
+		c.Precede(X, tf.NewTask("done", func() { done = true }))
+		return tf, X
+	}
+
+	for _, executor := range []gotaskflow.Executor{gotaskflow.NewExecutor(4), gotaskflow.NewSimulator(1)} {
+		tf, X := newFlow()
+		executor.RunFrom(tf, X).Wait()
+		if xs != 3 || !done {
+			t.Errorf("expected X to run 3 times then done, got X %d times, done %v", xs, done)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package gotaskflow
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// ProfileFormat selects the output format of Executor.Profile.
+type ProfileFormat int
+
+const (
+	// ProfileFolded writes folded stacks, one line per task, for flamegraph.pl and similar tools.
+	ProfileFolded ProfileFormat = iota
+	// ProfilePprof writes a gzipped pprof protobuf readable by `go tool pprof`.
+	// Stacks follow the subflow -> task hierarchy, values are invocation counts and wall time,
+	// samples carry the task labels.
+	ProfilePprof
+)
+
+// drawPprof writes the profile in the gzipped profile.proto format.
+func (t *profiler) drawPprof(w io.Writer) error {
+	t.mu.Lock()
+	p := newPprofBuilder()
+	keys := make([]attr, 0, len(t.spans))
+	for k := range t.spans {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].path != keys[j].path {
+			return keys[i].path < keys[j].path
+		}
+		if keys[i].name != keys[j].name {
+			return keys[i].name < keys[j].name
+		}
+		if keys[i].typ != keys[j].typ {
+			return keys[i].typ < keys[j].typ
+		}
+		return !keys[i].cached && keys[j].cached
+	})
+	for _, k := range keys {
+		s := t.spans[k]
+		var stack []uint64 // leaf first
+		for cur := s; cur != nil; cur = cur.parent {
+			stack = append(stack, p.location(cur.extra))
+		}
+		sample := pprofSample{locations: stack, values: []int64{t.counts[k], s.cost.Nanoseconds()}}
+		if s.info != nil {
+			sample.labels = s.info.Labels
+		}
+		p.samples = append(p.samples, sample)
+	}
+	t.mu.Unlock()
+
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(p.encode()); err != nil {
+		return fmt.Errorf("write profile -> %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("write profile -> %w", err)
+	}
+	return nil
+}
+
+type pprofSample struct {
+	locations []uint64
+	values    []int64
+	labels    map[string]string // task labels
+}
+
+// pprofBuilder encodes a profile.proto message, see
+// https://github.com/google/pprof/blob/main/proto/profile.proto.
+// Every task gets one function, named by its path, and one location sharing the same ID;
+// its executions restored from cache get another one.
+type pprofBuilder struct {
+	strings   []string
+	stringIDs map[string]int64
+	frames    []attr
+	frameIDs  map[attr]uint64
+	samples   []pprofSample
+}
+
+func newPprofBuilder() *pprofBuilder {
+	return &pprofBuilder{
+		strings:   []string{""},
+		stringIDs: map[string]int64{"": 0},
+		frameIDs:  make(map[attr]uint64),
+	}
+}
+
+func (p *pprofBuilder) str(s string) int64 {
+	if id, ok := p.stringIDs[s]; ok {
+		return id
+	}
+	id := int64(len(p.strings))
+	p.strings = append(p.strings, s)
+	p.stringIDs[s] = id
+	return id
+}
+
+func (p *pprofBuilder) location(a attr) uint64 {
+	if id, ok := p.frameIDs[a]; ok {
+		return id
+	}
+	p.frames = append(p.frames, a)
+	id := uint64(len(p.frames))
+	p.frameIDs[a] = id
+	return id
+}
+
+// profile.proto field numbers.
+const (
+	pprofSampleType        = 1
+	pprofSampleField       = 2
+	pprofLocation          = 4
+	pprofFunction          = 5
+	pprofStringTable       = 6
+	pprofTimeNanos         = 9
+	pprofPeriodType        = 11
+	pprofPeriod            = 12
+	pprofDefaultSampleType = 14
+)
+
+func (p *pprofBuilder) encode() []byte {
+	var b protoBuffer
+	valueType := func(field int, typ, unit string) {
+		var vt protoBuffer
+		vt.int64(1, p.str(typ))
+		vt.int64(2, p.str(unit))
+		b.message(field, vt)
+	}
+	valueType(pprofSampleType, "invocations", "count")
+	valueType(pprofSampleType, "wall", "nanoseconds")
+
+	for _, s := range p.samples {
+		var sb protoBuffer
+		sb.packedUint64(1, s.locations)
+		sb.packedInt64(2, s.values)
+		keys := make([]string, 0, len(s.labels))
+		for k := range s.labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			var lb protoBuffer
+			lb.int64(1, p.str(k))
+			lb.int64(2, p.str(s.labels[k]))
+			sb.message(3, lb)
+		}
+		b.message(pprofSampleField, sb)
+	}
+	for i := range p.frames {
+		var line, loc protoBuffer
+		line.uint64(1, uint64(i+1))
+		loc.uint64(1, uint64(i+1))
+		loc.message(4, line)
+		b.message(pprofLocation, loc)
+	}
+	for i, f := range p.frames {
+		var fn protoBuffer
+		fn.uint64(1, uint64(i+1))
+		name := f.path
+		if name == "" {
+			name = f.name
+		}
+		if f.cached {
+			name += cachedSuffix
+		}
+		fn.int64(2, p.str(name))
+		fn.int64(3, p.str(f.name))
+		fn.int64(4, p.str(string(f.typ)))
+		b.message(pprofFunction, fn)
+	}
+
+	// intern every string before writing the table
+	wall := p.str("wall")
+	valueType(pprofPeriodType, "wall", "nanoseconds")
+	for _, s := range p.strings {
+		b.bytes(pprofStringTable, []byte(s))
+	}
+	b.int64(pprofTimeNanos, time.Now().UnixNano())
+	b.int64(pprofPeriod, 1)
+	b.int64(pprofDefaultSampleType, wall)
+	return b.buf
+}
+
+// protoBuffer appends protobuf wire-format fields.
+type protoBuffer struct {
+	buf []byte
+}
+
+func (b *protoBuffer) varint(v uint64) {
+	for v >= 0x80 {
+		b.buf = append(b.buf, byte(v)|0x80)
+		v >>= 7
+	}
+	b.buf = append(b.buf, byte(v))
+}
+
+func (b *protoBuffer) tag(field, wireType int) {
+	b.varint(uint64(field)<<3 | uint64(wireType))
+}
+
+func (b *protoBuffer) uint64(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	b.tag(field, 0)
+	b.varint(v)
+}
+
+func (b *protoBuffer) int64(field int, v int64) {
+	b.uint64(field, uint64(v))
+}
+
+func (b *protoBuffer) bytes(field int, v []byte) {
+	b.tag(field, 2)
+	b.varint(uint64(len(v)))
+	b.buf = append(b.buf, v...)
+}
+
+func (b *protoBuffer) message(field int, m protoBuffer) {
+	b.bytes(field, m.buf)
+}
+
+func (b *protoBuffer) packedUint64(field int, vs []uint64) {
+	var p protoBuffer
+	for _, v := range vs {
+		p.varint(v)
+	}
+	b.bytes(field, p.buf)
+}
+
+func (b *protoBuffer) packedInt64(field int, vs []int64) {
+	var p protoBuffer
+	for _, v := range vs {
+		p.varint(uint64(v))
+	}
+	b.bytes(field, p.buf)
+}
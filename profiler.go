@@ -10,15 +10,18 @@ import (
 )
 
 type profiler struct {
-	spans map[attr]*span
+	NopObserver
+	spans  map[attr]*span
+	counts map[attr]int64 // invocations per task
 
 	mu *sync.Mutex
 }
 
 func newProfiler() *profiler {
 	return &profiler{
-		spans: make(map[attr]*span),
-		mu:    &sync.Mutex{},
+		spans:  make(map[attr]*span),
+		counts: make(map[attr]int64),
+		mu:     &sync.Mutex{},
 	}
 }
 
@@ -30,11 +33,33 @@ func (t *profiler) AddSpan(s *span) {
 		s.cost += span.cost
 	}
 	t.spans[s.extra] = s
+	t.counts[s.extra]++
 }
 
+// OnTaskEnd accumulates the cost of every task that did not panic.
+func (t *profiler) OnTaskEnd(info *TaskInfo, outcome TaskOutcome) {
+	if outcome == TaskFailed {
+		return
+	}
+	t.AddSpan(spanOf(info, outcome))
+}
+
+// attr identifies a task in profiles, tasks sharing a name are told apart by their path.
 type attr struct {
-	typ  nodeType
-	name string
+	typ    nodeType
+	name   string
+	path   string
+	cached bool // executions restored from cache are accounted apart
+}
+
+// cachedSuffix marks the frames of cached executions in profiles.
+const cachedSuffix = " (cached)"
+
+func (a attr) label() string {
+	if a.cached {
+		return a.name + cachedSuffix
+	}
+	return a.name
 }
 
 type span struct {
@@ -43,10 +68,12 @@ type span struct {
 	cost       time.Duration
 	parent     *span
 	dependents []string // names of predecessor tasks
+	outcome    TaskOutcome
+	info       *TaskInfo
 }
 
 func (s *span) String() string {
-	return fmt.Sprintf("%s,%s,cost %v", s.extra.typ, s.extra.name, utils.NormalizeDuration(s.cost))
+	return fmt.Sprintf("%s,%s,cost %v", s.extra.typ, s.extra.label(), utils.NormalizeDuration(s.cost))
 }
 
 func (t *profiler) draw(w io.Writer) error {
@@ -74,48 +101,3 @@ func (t *profiler) draw(w io.Writer) error {
 	}
 	return nil
 }
-
-// observer 观察任务执行并记录 span
-type observer struct {
-	profiler *profiler
-	tracer   *tracer
-}
-
-func newObserver() *observer {
-	return &observer{}
-}
-
-// openSpan 创建 span（如果需要观察）
-func (o *observer) openSpan(node *innerNode, parent *span) *span {
-	if o.profiler == nil && o.tracer == nil {
-		return nil
-	}
-	return &span{
-		extra:      attr{typ: node.Typ, name: node.name},
-		begin:      time.Now(),
-		parent:     parent,
-		dependents: getDependentNames(node),
-	}
-}
-
-// closeSpan 结束 span 并记录
-func (o *observer) closeSpan(s *span, ok bool) {
-	if s == nil {
-		return
-	}
-	s.cost = time.Since(s.begin)
-	if ok && o.profiler != nil {
-		o.profiler.AddSpan(s)
-	}
-	if o.tracer != nil {
-		o.tracer.AddEvent(s)
-	}
-}
-
-func (o *observer) withProfiler(p *profiler) {
-	o.profiler = p
-}
-
-func (o *observer) withTracer(t *tracer) {
-	o.tracer = t
-}
@@ -2,6 +2,10 @@ package gotaskflow
 
 import (
 	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"slices"
 	"sync"
 	"testing"
 	"time"
@@ -264,3 +268,114 @@ func TestProfilerConcurrentAddSpan(t *testing.T) {
 		t.Errorf("expected merged cost >= %v, got %v", expectedMin, mergedSpan.cost)
 	}
 }
+
+func TestProfilerDrawPprof(t *testing.T) {
+	profiler := newProfiler()
+	parent := &span{extra: attr{typ: nodeSubflow, name: "sub"}, cost: time.Millisecond}
+	child := &span{extra: attr{typ: nodeStatic, name: "child"}, cost: 5 * time.Millisecond, parent: parent}
+	profiler.AddSpan(parent)
+	profiler.AddSpan(child)
+	profiler.AddSpan(&span{extra: child.extra, cost: 5 * time.Millisecond, parent: parent})
+
+	var buf bytes.Buffer
+	if err := profiler.drawPprof(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("output is not gzipped: %v", err)
+	}
+	raw, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields := decodeProtoFields(t, raw)
+	var strs []string
+	for _, f := range fields[pprofStringTable] {
+		strs = append(strs, string(f))
+	}
+	if len(strs) == 0 || strs[0] != "" {
+		t.Fatalf("string table must start with the empty string, got %q", strs)
+	}
+	for _, want := range []string{"invocations", "wall", "nanoseconds", "sub", "child"} {
+		if !slices.Contains(strs, want) {
+			t.Errorf("string table misses %q: %q", want, strs)
+		}
+	}
+	if n := len(fields[pprofSampleField]); n != 2 {
+		t.Fatalf("expected 2 samples, got %d", n)
+	}
+	if n := len(fields[pprofLocation]); n != 2 {
+		t.Errorf("expected 2 locations, got %d", n)
+	}
+
+	// samples are sorted by task name: child first, with stack child <- sub
+	sample := decodeProtoFields(t, fields[pprofSampleField][0])
+	locs, values := decodeVarints(sample[1][0]), decodeVarints(sample[2][0])
+	if len(locs) != 2 {
+		t.Errorf("expected stack of depth 2, got %v", locs)
+	}
+	if values[0] != 2 || values[1] != uint64(10*time.Millisecond) {
+		t.Errorf("expected 2 invocations over 10ms, got %v", values)
+	}
+}
+
+// decodeProtoFields returns the length-delimited fields of a protobuf message by field number.
+func decodeProtoFields(t *testing.T, b []byte) map[int][][]byte {
+	fields := make(map[int][][]byte)
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		b = b[n:]
+		switch key & 7 {
+		case 0:
+			_, n = binary.Uvarint(b)
+			b = b[n:]
+		case 2:
+			l, n := binary.Uvarint(b)
+			fields[int(key>>3)] = append(fields[int(key>>3)], b[n:n+int(l)])
+			b = b[n+int(l):]
+		default:
+			t.Fatalf("unexpected wire type %d", key&7)
+		}
+	}
+	return fields
+}
+
+func decodeVarints(b []byte) []uint64 {
+	var vs []uint64
+	for len(b) > 0 {
+		v, n := binary.Uvarint(b)
+		vs = append(vs, v)
+		b = b[n:]
+	}
+	return vs
+}
+
+func TestProfilerPprofLabels(t *testing.T) {
+	profiler := newProfiler()
+	info := &TaskInfo{Labels: map[string]string{"owner": "infra"}}
+	profiler.AddSpan(&span{extra: attr{typ: nodeStatic, name: "A", path: "G/A"}, cost: time.Millisecond, info: info})
+
+	var buf bytes.Buffer
+	if err := profiler.drawPprof(&buf); err != nil {
+		t.Fatal(err)
+	}
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := decodeProtoFields(t, raw)
+	var strs []string
+	for _, f := range fields[pprofStringTable] {
+		strs = append(strs, string(f))
+	}
+	sample := decodeProtoFields(t, fields[pprofSampleField][0])
+	if len(sample[3]) != 1 || !slices.Contains(strs, "owner") || !slices.Contains(strs, "infra") || !slices.Contains(strs, "G/A") {
+		t.Errorf("expected the sample labeled owner=infra and the function named by path, strings %q", strs)
+	}
+}
@@ -0,0 +1,161 @@
+package gotaskflow
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// Runtime gives a task created by NewRuntimeTask access to the executor running it, e.g. for adaptive
+// algorithms to expand work as they discover it. It is only valid while the task runs.
+type Runtime struct {
+	host    runtimeHost
+	node    *innerNode
+	span    *span
+	worker  int
+	attempt int
+}
+
+// runtimeHost is the executor a Runtime schedules tasks on.
+type runtimeHost interface {
+	schedule(nodes ...*innerNode)
+	// corun runs tf for the task node, keeping its worker busy with ready tasks meanwhile.
+	corun(tf *TaskFlow, node *innerNode, s *span, worker int) error
+}
+
+// fn returns the function running one execution of node on worker.
+func (p *Static) fn(host runtimeHost, node *innerNode, s *span, worker int) func() {
+	if p.runtime == nil {
+		return p.handle
+	}
+	rt := &Runtime{host: host, node: node, span: s, worker: worker}
+	if s != nil {
+		rt.attempt = s.info.Iteration
+	} else if node.g.run != nil {
+		rt.attempt = node.g.run.iteration(node.path())
+	}
+	return func() { p.runtime(rt) }
+}
+
+// Task returns the running task.
+func (rt *Runtime) Task() *Task {
+	return rt.node.task
+}
+
+// Path returns the qualified name of the running task, see Task.Path.
+func (rt *Runtime) Path() string {
+	return rt.node.path()
+}
+
+// Attempt counts the executions of the running task started before this one in the run,
+// as TaskInfo.Iteration does.
+func (rt *Runtime) Attempt() int {
+	return rt.attempt
+}
+
+// Worker returns the ID of the worker running the task, starting at 1.
+func (rt *Runtime) Worker() int {
+	return rt.worker
+}
+
+// Canceled reports whether a panic canceled the graph of the running task, or its run.
+func (rt *Runtime) Canceled() bool {
+	return rt.node.g.canceled.Load() || rt.node.g.root().canceled.Load()
+}
+
+// Schedule schedules task if it is idle: neither scheduled nor running, and not waiting for predecessors,
+// such as a successor of a condition that took another branch. It reports whether task was scheduled,
+// and panics if task is not in the same graph as the running task.
+func (rt *Runtime) Schedule(task *Task) bool {
+	n := task.node
+	if n.g != rt.node.g {
+		panic(fmt.Sprintf("task %q is not in the graph of task %q", task.Name(), rt.node.name))
+	}
+	n.mu.Lock()
+	idle := n.recyclable() && n.state.Load() == kNodeStateIdle && n.g.run.selected(n)
+	if idle {
+		n.state.Store(kNodeStateWaiting)
+	}
+	n.mu.Unlock()
+	if idle {
+		rt.host.schedule(n)
+	}
+	return idle
+}
+
+// CoRun runs tf and returns once it is done. Meanwhile the worker of the running task runs ready tasks
+// instead of blocking, so flows can nest deeper than the executor has workers. Tasks of tf belong to
+// the run of the running task. It returns an error if a panic canceled tf, or if tf failed the
+// validation enabled by WithValidation and was not run.
+func (rt *Runtime) CoRun(tf *TaskFlow) error {
+	return rt.host.corun(tf, rt.node, rt.span, rt.worker)
+}
+
+// coRunState returns the run state of tf co-run by node, sharing the RunInfo of node.
+func coRunState(tf *TaskFlow, node *innerNode) *runState {
+	rs := newRunState(tf, "")
+	if node.g.run != nil {
+		rs.info = node.g.run.info
+	}
+	return rs
+}
+
+func (e *innerExecutorImpl) corun(tf *TaskFlow, node *innerNode, s *span, worker int) error {
+	if e.validate {
+		if err := tf.Validate(); err != nil {
+			return fmt.Errorf("taskflow %q not run -> %w", tf.Name(), err)
+		}
+	}
+	tf.frozen = true
+	g := tf.graph
+	g.run = coRunState(tf, node)
+	g.setup()
+	slices.SortFunc(g.entries, func(i, j *innerNode) int {
+		return cmp.Compare(i.priority, j.priority)
+	})
+	e.schedule(g.entries...)
+
+	for {
+		g.scheCond.L.Lock()
+		e.mu.Lock()
+		for !g.recyclable() && e.wq.Len() == 0 && !g.canceled.Load() {
+			e.mu.Unlock()
+			g.scheCond.Wait()
+			e.mu.Lock()
+		}
+		g.scheCond.L.Unlock()
+
+		if g.recyclable() || g.canceled.Load() {
+			e.mu.Unlock()
+			break
+		}
+		n := e.wq.Pop()
+		e.mu.Unlock()
+
+		// run the task on this worker rather than waiting for another one
+		e.task(n, s)(worker)
+	}
+	if g.canceled.Load() {
+		return fmt.Errorf("taskflow %q canceled", tf.Name())
+	}
+	return nil
+}
+
+func (s *Simulator) corun(tf *TaskFlow, node *innerNode, sp *span, _ int) error {
+	if s.e.validate {
+		if err := tf.Validate(); err != nil {
+			return fmt.Errorf("taskflow %q not run -> %w", tf.Name(), err)
+		}
+	}
+	tf.frozen = true
+	tf.graph.run = coRunState(tf, node)
+	s.spans[tf.graph] = sp
+	joined := false
+	s.scheduleGraph(tf.graph, func() { joined = true })
+	for !joined && s.step() {
+	}
+	if !joined || tf.graph.canceled.Load() {
+		return fmt.Errorf("taskflow %q canceled", tf.Name())
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package gotaskflow_test
+
+import (
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gotaskflow "github.com/noneback/go-taskflow"
+)
+
+// runWithin runs tf on executor, failing t if it does not end within a few seconds.
+func runWithin(t *testing.T, executor gotaskflow.Executor, tf *gotaskflow.TaskFlow) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		executor.Run(tf).Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("run of %q did not end", tf.Name())
+	}
+}
+
+func TestRuntimeInfo(t *testing.T) {
+	var (
+		i        int
+		attempts []int
+		paths    []string
+	)
+	tf := gotaskflow.NewTaskFlow("G")
+	init := tf.NewTask("init", func() { i = 0 })
+	sub := tf.NewSubflow("S", func(sf *gotaskflow.Subflow) {
+		sf.NewRuntimeTask("rt", func(rt *gotaskflow.Runtime) {
+			attempts = append(attempts, rt.Attempt())
+			paths = append(paths, rt.Path())
+			if rt.Task().Name() != "rt" || rt.Worker() < 1 || rt.Canceled() {
+				t.Errorf("unexpected runtime: task %q, worker %d, canceled %v", rt.Task().Name(), rt.Worker(), rt.Canceled())
+			}
+		})
+	})
+	again := tf.NewCondition("again", func() uint {
+		i++
+		if i < 3 {
+			return 0
+		}
+		return 1
+	})
+	init.Precede(sub)
+	sub.Precede(again)
+	again.Precede(sub, tf.NewTask("done", func() {}))
+
+	runWithin(t, gotaskflow.NewExecutor(4), tf)
+	if !slices.Equal(attempts, []int{0, 1, 2}) {
+		t.Errorf("attempts %v, want [0 1 2]", attempts)
+	}
+	if paths[0] != "G/S/rt" {
+		t.Errorf("path %q, want G/S/rt", paths[0])
+	}
+}
+
+func TestRuntimeSchedule(t *testing.T) {
+	var b atomic.Int32
+	tf := gotaskflow.NewTaskFlow("G")
+	cond := tf.NewCondition("cond", func() uint { return 0 })
+	var B *gotaskflow.Task
+	A := tf.NewRuntimeTask("A", func(rt *gotaskflow.Runtime) {
+		if rt.Schedule(rt.Task()) {
+			t.Error("running task scheduled again")
+		}
+		if !rt.Schedule(B) {
+			t.Error("idle task B not scheduled")
+		}
+	})
+	B = tf.NewTask("B", func() { b.Add(1) })
+	cond.Precede(A, B)
+
+	runWithin(t, gotaskflow.NewExecutor(2), tf)
+	if b.Load() != 1 {
+		t.Errorf("B ran %d times, want 1", b.Load())
+	}
+}
+
+// newCoRunFlow returns a flow co-running a flow of depth nested flows, counting the leaves run.
+func newCoRunFlow(t *testing.T, name string, depth int, leaves *atomic.Int32) *gotaskflow.TaskFlow {
+	tf := gotaskflow.NewTaskFlow(name)
+	for _, leaf := range []string{"x", "y"} {
+		if depth == 0 {
+			tf.NewTask(leaf, func() { leaves.Add(1) })
+			continue
+		}
+		tf.NewRuntimeTask(leaf, func(rt *gotaskflow.Runtime) {
+			if err := rt.CoRun(newCoRunFlow(t, name+"-"+leaf, depth-1, leaves)); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	return tf
+}
+
+func TestRuntimeCoRun(t *testing.T) {
+	var leaves atomic.Int32
+	runWithin(t, gotaskflow.NewExecutor(1), newCoRunFlow(t, "G", 3, &leaves))
+	if leaves.Load() != 16 {
+		t.Errorf("ran %d leaves, want 16", leaves.Load())
+	}
+
+	leaves.Store(0)
+	gotaskflow.NewSimulator(1).Run(newCoRunFlow(t, "G", 3, &leaves))
+	if leaves.Load() != 16 {
+		t.Errorf("simulator ran %d leaves, want 16", leaves.Load())
+	}
+}
+
+func TestRuntimeCoRunCanceled(t *testing.T) {
+	var err error
+	tf := gotaskflow.NewTaskFlow("G")
+	tf.NewRuntimeTask("rt", func(rt *gotaskflow.Runtime) {
+		nested := gotaskflow.NewTaskFlow("nested")
+		nested.NewTask("boom", func() { panic("boom") })
+		err = rt.CoRun(nested)
+	})
+
+	runWithin(t, gotaskflow.NewExecutor(2), tf)
+	if err == nil {
+		t.Error("expected an error co-running a canceled flow")
+	}
+}
@@ -0,0 +1,62 @@
+package gotaskflow
+
+import (
+	"cmp"
+	"slices"
+	"sync/atomic"
+)
+
+var semaphoreIDs atomic.Uint64
+
+// Semaphore limits how many of the tasks acquiring it run at once, e.g. to bound the tasks doing I/O:
+//
+//	io := NewSemaphore(2)
+//	for _, task := range tf.Select(Labeled("io")) {
+//		task.Acquire(io)
+//	}
+//
+// A task waits on its worker until every semaphore it acquires has room, and releases them once it
+// returns. A subflow only holds them while it instantiates its tasks.
+type Semaphore struct {
+	id     uint64 // order of acquisition, so that tasks acquiring several semaphores do not deadlock
+	tokens chan struct{}
+}
+
+// NewSemaphore returns a Semaphore letting n tasks run at once.
+func NewSemaphore(n int) *Semaphore {
+	if n <= 0 {
+		panic("semaphore limit must be positive")
+	}
+	return &Semaphore{id: semaphoreIDs.Add(1), tokens: make(chan struct{}, n)}
+}
+
+// Limit returns the number of tasks the semaphore lets run at once.
+func (s *Semaphore) Limit() int {
+	return cap(s.tokens)
+}
+
+// Acquire makes the task hold sems while it runs.
+func (t *Task) Acquire(sems ...*Semaphore) *Task {
+	for _, s := range sems {
+		if !slices.Contains(t.node.semaphores, s) {
+			t.node.semaphores = append(t.node.semaphores, s)
+		}
+	}
+	slices.SortFunc(t.node.semaphores, func(a, b *Semaphore) int {
+		return cmp.Compare(a.id, b.id)
+	})
+	return t
+}
+
+// hold calls f holding the semaphores of n.
+func (n *innerNode) hold(f func()) {
+	for _, s := range n.semaphores {
+		s.tokens <- struct{}{}
+	}
+	defer func() {
+		for i := len(n.semaphores) - 1; i >= 0; i-- {
+			<-n.semaphores[i].tokens
+		}
+	}()
+	f()
+}
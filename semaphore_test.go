@@ -0,0 +1,47 @@
+package gotaskflow_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gotaskflow "github.com/noneback/go-taskflow"
+)
+
+func TestSemaphore(t *testing.T) {
+	tf := gotaskflow.NewTaskFlow("G")
+	var running, peak atomic.Int32
+	for i := 0; i < 8; i++ {
+		tf.NewTask("", func() {
+			n := running.Add(1)
+			for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+		}).Label("io", "")
+	}
+	other := false
+	tf.NewTask("cpu", func() { other = true })
+
+	io := gotaskflow.NewSemaphore(2)
+	for _, task := range tf.Select(gotaskflow.Labeled("io")) {
+		task.Acquire(io)
+	}
+	gotaskflow.NewExecutor(8).Run(tf).Wait()
+
+	if peak.Load() != 2 || !other {
+		t.Errorf("expected at most 2 io tasks at once, got %d", peak.Load())
+	}
+}
+
+func TestSemaphoreLimit(t *testing.T) {
+	if gotaskflow.NewSemaphore(3).Limit() != 3 {
+		t.Error("unexpected limit")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for a zero limit")
+		}
+	}()
+	gotaskflow.NewSemaphore(0)
+}
@@ -0,0 +1,431 @@
+package gotaskflow
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/noneback/go-taskflow/utils"
+)
+
+// VirtualClock is the clock of a Simulator. It starts at the Unix epoch and only advances when tasks sleep,
+// so timeouts and backoffs measured with it are reproducible and cost no real time.
+type VirtualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// Now returns the virtual time.
+func (c *VirtualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Since returns the virtual time elapsed since t.
+func (c *VirtualClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// Sleep advances the virtual time by d without blocking.
+func (c *VirtualClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d > 0 {
+		c.now = c.now.Add(d)
+	}
+}
+
+// Simulator is an Executor running tasks one at a time on the calling goroutine. The next task is picked
+// at random among the ready tasks of the highest priority, from a seeded source: with the same seed and
+// the same task behavior, runs execute tasks in the same order, so a failing interleaving can be replayed.
+// Conditions, subflows, priorities, cancellation on panic and options behave as with NewExecutor;
+// spans are timed by the virtual clock, and every task runs on worker 1.
+type Simulator struct {
+	e     *innerExecutorImpl // holds the options
+	seed  int64
+	rng   *rand.Rand
+	clock *VirtualClock
+
+	ready []*innerNode
+	spans map[*eGraph]*span  // span of the subflow execution running each graph
+	joins map[*eGraph]func() // called once each running graph has no task left
+}
+
+// NewSimulator returns a Simulator picking tasks from seed, with the same options as NewExecutor.
+func NewSimulator(seed int64, opts ...Option) *Simulator {
+	e := &innerExecutorImpl{
+		concurrency: 1,
+		wq:          utils.NewQueue[*innerNode](false),
+		wg:          &sync.WaitGroup{},
+		mu:          &sync.Mutex{},
+		obs:         newObserver(),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.pool = utils.NewCopool(e.concurrency)
+
+	s := &Simulator{
+		e:     e,
+		seed:  seed,
+		rng:   rand.New(rand.NewSource(seed)),
+		clock: &VirtualClock{now: time.Unix(0, 0)},
+		spans: make(map[*eGraph]*span),
+		joins: make(map[*eGraph]func()),
+	}
+	e.obs.now = s.clock.Now
+	if e.obs.tracer != nil {
+		e.obs.tracer.start = s.clock.Now()
+	}
+	return s
+}
+
+// Seed returns the seed the simulator was created with.
+func (s *Simulator) Seed() int64 {
+	return s.seed
+}
+
+// Clock returns the virtual clock, for tasks to measure time and sleep.
+func (s *Simulator) Clock() *VirtualClock {
+	return s.clock
+}
+
+// Run executes taskflow until no task is left, Run returns once it is done.
+func (s *Simulator) Run(tf *TaskFlow) Executor {
+	s.run(tf, newRunState(tf, ""))
+	return s
+}
+
+// Resume runs taskflow as the run identified by runID, skipping checkpointed task executions.
+func (s *Simulator) Resume(tf *TaskFlow, runID string) Executor {
+	if s.e.ckpt == nil {
+		panic("checkpoint store is not configured, use WithCheckpoint")
+	}
+	recs, err := s.e.ckpt.Load(runID)
+	if err != nil {
+		log.Printf("[go-taskflow] cannot resume run %q of graph %q: %v", runID, tf.Name(), err)
+		return s
+	}
+
+	rs := newRunState(tf, runID)
+	rs.ckpt = newCheckpointRun(runID, s.e.ckpt, recs)
+	s.run(tf, rs)
+	return s
+}
+
+// RunTargets runs the tasks named targets and their transitive predecessors, skipping the others.
+func (s *Simulator) RunTargets(tf *TaskFlow, targets ...string) Executor {
+	rs := newRunState(tf, "")
+	rs.only = selectTargets(tf, targets)
+	s.run(tf, rs)
+	return s
+}
+
+// RunFrom runs task and its transitive successors, assuming its predecessors already succeeded.
+func (s *Simulator) RunFrom(tf *TaskFlow, task *Task) Executor {
+	rs := newRunState(tf, "")
+	rs.only, rs.from = selectFrom(tf, task)
+	s.run(tf, rs)
+	return s
+}
+
+// DryRun predicts the schedule of taskflow on a single worker, see NewExecutor.
+func (s *Simulator) DryRun(tf *TaskFlow, plan DryRunPlan) *Schedule {
+	return s.e.DryRun(tf, plan)
+}
+
+// Wait returns at once, Run is synchronous.
+func (s *Simulator) Wait() {}
+
+// Profile write flame graph raw text into w, or a pprof profile if format is ProfilePprof
+func (s *Simulator) Profile(w io.Writer, format ...ProfileFormat) error {
+	return s.e.Profile(w, format...)
+}
+
+// Trace write Chrome Trace Event data into w
+func (s *Simulator) Trace(w io.Writer) error {
+	return s.e.Trace(w)
+}
+
+func (s *Simulator) run(tf *TaskFlow, rs *runState) {
+	if s.e.validate {
+		if err := tf.Validate(); err != nil {
+			log.Printf("[go-taskflow] graph %q not run: %v", tf.Name(), err)
+			s.e.obs.runRejected(rs.info, err)
+			return
+		}
+	}
+	tf.frozen = true
+	tf.graph.run = rs
+	rs.info.Begin = s.clock.Now()
+	s.e.obs.runStart(rs.info)
+
+	s.spans[tf.graph] = nil
+	s.scheduleGraph(tf.graph, func() {})
+	for s.step() {
+	}
+
+	rs.info.Cost = s.clock.Since(rs.info.Begin)
+	rs.info.Canceled = tf.graph.canceled.Load()
+	s.e.obs.runEnd(rs.info)
+}
+
+// step invokes a ready task, it returns false if none is left.
+func (s *Simulator) step() bool {
+	if len(s.ready) == 0 {
+		return false
+	}
+	priority := s.ready[0].priority
+	for _, n := range s.ready {
+		priority = min(priority, n.priority)
+	}
+	var candidates []int
+	for i, n := range s.ready {
+		if n.priority == priority {
+			candidates = append(candidates, i)
+		}
+	}
+	i := candidates[s.rng.Intn(len(candidates))]
+	node := s.ready[i]
+	s.ready = append(s.ready[:i], s.ready[i+1:]...)
+
+	switch p := node.ptr.(type) {
+	case *Static:
+		s.invokeStatic(node, p)
+	case *Subflow:
+		s.invokeSubflow(node, p)
+	case *Condition:
+		s.invokeCondition(node, p)
+	default:
+		panic("unsupported node")
+	}
+	return true
+}
+
+// protect calls f, returning what it panicked with and the stack of the panic.
+func protect(f func()) (r any, stack []byte) {
+	defer func() {
+		if r = recover(); r != nil {
+			stack = debug.Stack()
+		}
+	}()
+	f()
+	return nil, nil
+}
+
+func (s *Simulator) invokeStatic(node *innerNode, p *Static) {
+	sp := s.e.obs.openSpan(node, s.spans[node.g], 1)
+	outcome := TaskCanceled
+	r, stack := protect(func() {
+		if !node.g.canceled.Load() {
+			node.state.Store(kNodeStateRunning)
+			outcome = TaskRestored
+			if rec, done := node.g.run.replay(node); !done {
+				outcome = TaskFinished
+				if s.e.cached(node, func() { node.hold(p.fn(s, node, sp, 1)) }) {
+					outcome = TaskCached
+				}
+				node.g.run.record(node, rec)
+			}
+			node.state.Store(kNodeStateFinished)
+		}
+	})
+	if r != nil {
+		node.g.canceled.Store(true)
+		outcome = TaskFailed
+		log.Printf("[go-taskflow] graph %q canceled: static task %q panicked: %v\n%s", node.g.name, node.name, r, stack)
+	}
+	s.e.obs.endSpan(sp, outcome)
+	node.drop()
+	s.scheSuccessors(node)
+	s.done(node.g)
+}
+
+func (s *Simulator) invokeSubflow(node *innerNode, p *Subflow) {
+	if join, running := s.joins[p.g]; running {
+		// a detached subflow reached again, e.g. by a loop, waits for its previous execution
+		s.joins[p.g] = func() {
+			join()
+			s.invokeSubflow(node, p)
+		}
+		return
+	}
+	sp := s.e.obs.openSpan(node, s.spans[node.g], 1)
+	var (
+		rec     CheckpointRecord
+		done    bool // completed by a previous attempt of the run
+		outcome = TaskCanceled
+	)
+	r, stack := protect(func() {
+		if !node.g.canceled.Load() {
+			node.state.Store(kNodeStateRunning)
+			outcome = TaskRestored
+			if rec, done = node.g.run.replay(node); !done {
+				if p.stale(s.e.rebuild) {
+					node.g.run.forget(p.g)
+					p.g.clear() // discard the tasks of the previous execution
+					node.hold(func() { p.handle(p) })
+					p.g.instantiated = true
+					if s.e.validate {
+						if err := p.g.validate(); err != nil {
+							panic(err)
+						}
+					}
+				}
+				outcome = TaskFinished
+			}
+			node.state.Store(kNodeStateFinished)
+		}
+	})
+	if r != nil {
+		log.Printf("[go-taskflow] graph %q canceled: subflow %q panicked: %v\n%s", node.g.name, node.name, r, stack)
+		node.g.canceled.Store(true)
+		p.g.canceled.Store(true)
+		outcome = TaskFailed
+	}
+	s.e.obs.endSpan(sp, outcome)
+
+	finish := func() {
+		node.drop()
+		s.scheSuccessors(node)
+		s.done(node.g)
+	}
+	if done {
+		finish()
+		return
+	}
+	p.g.run = node.g.run
+	s.spans[p.g] = sp
+	if r == nil && p.g.detached {
+		root := node.g.root()
+		root.ref()
+		finish()
+		s.scheduleGraph(p.g, func() {
+			if p.g.canceled.Load() {
+				root.canceled.Store(true)
+			}
+			if !p.g.canceled.Load() && !root.canceled.Load() {
+				node.g.run.record(node, rec)
+			}
+			s.done(root)
+		})
+		return
+	}
+	s.scheduleGraph(p.g, func() {
+		if p.g.canceled.Load() {
+			node.g.canceled.Store(true)
+		}
+		if r == nil && !p.g.canceled.Load() && !node.g.canceled.Load() {
+			node.g.run.record(node, rec)
+		}
+		finish()
+	})
+}
+
+func (s *Simulator) invokeCondition(node *innerNode, p *Condition) {
+	sp := s.e.obs.openSpan(node, s.spans[node.g], 1)
+	var (
+		choice  uint
+		outcome = TaskCanceled
+	)
+	r, stack := protect(func() {
+		if !node.g.canceled.Load() {
+			node.state.Store(kNodeStateRunning)
+
+			rec, done := node.g.run.replay(node)
+			choice = rec.Choice
+			outcome = TaskRestored
+			if !done {
+				node.hold(func() { choice = p.handle() })
+				if choice > uint(len(p.mapper)) {
+					panic(fmt.Sprintln("condition task failed, successors of condition should be more than precondition choice", choice))
+				}
+				rec.Choice = choice
+				node.g.run.record(node, rec)
+				outcome = TaskFinished
+			}
+			node.state.Store(kNodeStateFinished)
+			s.schedule(p.mapper[choice])
+		}
+	})
+	if r != nil {
+		node.g.canceled.Store(true)
+		outcome = TaskFailed
+		log.Printf("[go-taskflow] graph %q canceled: condition task %q panicked: %v\n%s", node.g.name, node.name, r, stack)
+	}
+	if outcome == TaskFinished || outcome == TaskRestored {
+		s.e.obs.chose(sp, choice)
+	}
+	s.e.obs.endSpan(sp, outcome)
+	node.drop()
+	node.setup()
+	s.done(node.g)
+}
+
+func (s *Simulator) scheSuccessors(node *innerNode) {
+	candidate := make([]*innerNode, 0, len(node.successors))
+	for _, n := range node.successors {
+		if n.recyclable() && n.state.Load() == kNodeStateIdle {
+			n.state.Store(kNodeStateWaiting)
+			candidate = append(candidate, n)
+		}
+	}
+
+	slices.SortFunc(candidate, func(i, j *innerNode) int {
+		return cmp.Compare(i.priority, j.priority)
+	})
+	node.setup() // make node repeatable
+	s.schedule(candidate...)
+}
+
+func (s *Simulator) schedule(nodes ...*innerNode) {
+	for _, node := range nodes {
+		if node.g.canceled.Load() {
+			return
+		}
+		if !node.g.run.selected(node) {
+			continue
+		}
+		node.g.ref()
+		s.e.obs.scheduled(node)
+		s.ready = append(s.ready, node)
+	}
+}
+
+// scheduleGraph schedules the entries of g, join is called once g has no task left.
+func (s *Simulator) scheduleGraph(g *eGraph, join func()) {
+	g.setup()
+	slices.SortFunc(g.entries, func(i, j *innerNode) int {
+		return cmp.Compare(i.priority, j.priority)
+	})
+	s.joins[g] = join
+	s.schedule(g.entries...)
+	if g.recyclable() {
+		s.join(g)
+	}
+}
+
+// done releases a task of g, joining g if it was the last one.
+func (s *Simulator) done(g *eGraph) {
+	g.deref()
+	if g.recyclable() {
+		s.join(g)
+	}
+}
+
+func (s *Simulator) join(g *eGraph) {
+	join, ok := s.joins[g]
+	if !ok {
+		return
+	}
+	delete(s.joins, g)
+	delete(s.spans, g)
+	join()
+}
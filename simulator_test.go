@@ -0,0 +1,217 @@
+package gotaskflow_test
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gotaskflow "github.com/noneback/go-taskflow"
+)
+
+// runObserver records the runs of an executor.
+type runObserver struct {
+	gotaskflow.NopObserver
+	runs []*gotaskflow.RunInfo
+}
+
+func (o *runObserver) OnRunEnd(run *gotaskflow.RunInfo) {
+	o.runs = append(o.runs, run)
+}
+
+// newWideFlow returns a flow of a fan-out of 8 tasks, one subflow among them, recording the execution order.
+func newWideFlow(order *[]string) *gotaskflow.TaskFlow {
+	tf := gotaskflow.NewTaskFlow("wide")
+	record := func(name string) func() {
+		return func() { *order = append(*order, name) }
+	}
+	start := tf.NewTask("start", record("start"))
+	end := tf.NewTask("end", record("end"))
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		task := tf.NewTask(name, record(name))
+		start.Precede(task)
+		task.Precede(end)
+	}
+	sub := tf.NewSubflow("sub", func(sf *gotaskflow.Subflow) {
+		x, y := sf.NewTask("x", record("sub/x")), sf.NewTask("y", record("sub/y"))
+		x.Precede(y)
+	})
+	start.Precede(sub)
+	sub.Precede(end)
+	return tf
+}
+
+func TestSimulatorReplay(t *testing.T) {
+	orders := make(map[string]bool)
+	for seed := int64(0); seed < 8; seed++ {
+		var first, second []string
+		gotaskflow.NewSimulator(seed).Run(newWideFlow(&first)).Wait()
+		gotaskflow.NewSimulator(seed).Run(newWideFlow(&second)).Wait()
+
+		if strings.Join(first, ",") != strings.Join(second, ",") {
+			t.Errorf("seed %d: orders differ: %v and %v", seed, first, second)
+		}
+		if len(first) != 11 || first[0] != "start" || first[10] != "end" {
+			t.Errorf("seed %d: unexpected order %v", seed, first)
+		}
+		orders[strings.Join(first, ",")] = true
+	}
+	if len(orders) < 2 {
+		t.Errorf("expected seeds to interleave tasks differently, got %v", orders)
+	}
+}
+
+func TestSimulatorSemantics(t *testing.T) {
+	sim := gotaskflow.NewSimulator(42, gotaskflow.WithTracer())
+	tf := gotaskflow.NewTaskFlow("loop")
+
+	i := 0
+	init := tf.NewTask("init", func() {})
+	loop := tf.NewCondition("loop", func() uint {
+		if i < 3 {
+			return 0
+		}
+		return 1
+	})
+	body := tf.NewSubflow("body", func(sf *gotaskflow.Subflow) {
+		a, b := sf.NewTask("a", func() {}), sf.NewTask("b", func() { i++ })
+		a.Precede(b)
+	})
+	back := tf.NewCondition("back", func() uint { return 0 })
+	done := tf.NewTask("done", func() {})
+	init.Precede(loop)
+	loop.Precede(body, done)
+	body.Precede(back)
+	back.Precede(loop)
+	sim.Run(tf).Wait()
+
+	var trace bytes.Buffer
+	if err := sim.Trace(&trace); err != nil {
+		t.Fatal(err)
+	}
+	r, err := gotaskflow.Verify(&trace, tf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.OK() || r.Runs["body/b"] != 3 || r.Runs["loop"] != 4 || r.Runs["done"] != 1 {
+		t.Errorf("unexpected report %s, runs %v", r, r.Runs)
+	}
+}
+
+func TestSimulatorPriority(t *testing.T) {
+	for seed := int64(0); seed < 8; seed++ {
+		var order []string
+		tf := gotaskflow.NewTaskFlow("priority")
+		for _, p := range []gotaskflow.TaskPriority{gotaskflow.LOW, gotaskflow.NORMAL, gotaskflow.HIGH} {
+			name := p.String()
+			tf.NewTask(name, func() { order = append(order, name) }).Priority(p)
+		}
+		gotaskflow.NewSimulator(seed).Run(tf)
+
+		if strings.Join(order, ",") != "high,normal,low" {
+			t.Errorf("seed %d: expected tasks by priority, got %v", seed, order)
+		}
+	}
+}
+
+func TestSimulatorCancel(t *testing.T) {
+	obs := &runObserver{}
+	tf := gotaskflow.NewTaskFlow("cancel")
+	ran := false
+	sub := tf.NewSubflow("sub", func(sf *gotaskflow.Subflow) {
+		sf.NewTask("panic", func() { panic("boom") })
+	})
+	after := tf.NewTask("after", func() { ran = true })
+	sub.Precede(after)
+	gotaskflow.NewSimulator(1, gotaskflow.WithObserver(obs)).Run(tf)
+
+	if ran {
+		t.Error("expected the task after a panicking subflow not to run")
+	}
+	if len(obs.runs) != 1 || !obs.runs[0].Canceled {
+		t.Errorf("expected a canceled run, got %+v", obs.runs)
+	}
+}
+
+func simulatedPanic() { panic("boom") }
+
+func TestSimulatorPanicStack(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	tf := gotaskflow.NewTaskFlow("G")
+	tf.NewTask("A", simulatedPanic)
+	gotaskflow.NewSimulator(1).Run(tf)
+
+	if !strings.Contains(buf.String(), "go-taskflow_test.simulatedPanic") {
+		t.Errorf("expected the logged stack to point at the panic, got:\n%s", buf.String())
+	}
+}
+
+func TestSimulatorVirtualClock(t *testing.T) {
+	obs := &runObserver{}
+	sim := gotaskflow.NewSimulator(1, gotaskflow.WithObserver(obs))
+	tf := gotaskflow.NewTaskFlow("backoff")
+
+	var waited time.Duration
+	attempt := 0
+	fetch := tf.NewTask("fetch", func() {
+		begin := sim.Clock().Now()
+		sim.Clock().Sleep(time.Duration(1<<attempt) * time.Second) // exponential backoff
+		attempt++
+		waited += sim.Clock().Since(begin)
+	})
+	retry := tf.NewCondition("retry", func() uint {
+		if attempt < 3 {
+			return 0
+		}
+		return 1
+	})
+	tf.NewTask("init", func() {}).Precede(fetch)
+	fetch.Precede(retry)
+	retry.Precede(fetch, tf.NewTask("done", func() {}))
+
+	begin := time.Now()
+	sim.Run(tf)
+	if time.Since(begin) > time.Second {
+		t.Error("expected the virtual clock not to block")
+	}
+	if waited != 7*time.Second || len(obs.runs) != 1 || obs.runs[0].Cost != 7*time.Second {
+		t.Errorf("expected 7s of virtual time, got %v, runs %+v", waited, obs.runs)
+	}
+}
+
+func TestSimulatorDetached(t *testing.T) {
+	early := false
+	for seed := int64(0); seed < 20; seed++ {
+		var order []string
+		tf := gotaskflow.NewTaskFlow("G")
+		S := tf.NewSubflow("S", func(sf *gotaskflow.Subflow) {
+			sf.Detach()
+			sf.NewTask("x", func() { order = append(order, "S/x") })
+		})
+		B := tf.NewTask("B", func() { order = append(order, "B") })
+		S.Precede(B)
+
+		gotaskflow.NewSimulator(seed).Run(tf)
+		if len(order) != 2 {
+			t.Fatalf("seed %d: ran %v, want S/x and B", seed, order)
+		}
+		early = early || order[0] == "B"
+	}
+	if !early {
+		t.Error("successor of a detached subflow never ran before its tasks")
+	}
+
+	for seed := int64(0); seed < 20; seed++ {
+		var runs atomic.Int32
+		gotaskflow.NewSimulator(seed).Run(newDetachedLoop(&runs, seed%2 == 0, 0))
+		if runs.Load() != 3 {
+			t.Errorf("seed %d: detached subflow in a loop ran %d times, want 3", seed, runs.Load())
+		}
+	}
+}
@@ -0,0 +1,362 @@
+package gotaskflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// FlowSpec declares a TaskFlow outside Go code, built by Registry.Build. ParseFlowSpec reads it in JSON
+// or YAML, fields also carry yaml tags for YAML packages.
+type FlowSpec struct {
+	Name  string     `json:"name" yaml:"name"`
+	Tasks []TaskSpec `json:"tasks" yaml:"tasks"`
+}
+
+// TaskSpec declares a task of a FlowSpec.
+type TaskSpec struct {
+	Name     string     `json:"name" yaml:"name"`
+	Kind     string     `json:"kind,omitempty" yaml:"kind,omitempty"`         // static (default), condition or subflow
+	Func     string     `json:"func,omitempty" yaml:"func,omitempty"`         // registry entry implementing the task, defaults to Name
+	Priority string     `json:"priority,omitempty" yaml:"priority,omitempty"` // high, normal (default) or low
+	Precede  []string   `json:"precede,omitempty" yaml:"precede,omitempty"`   // successors, in branch order for a condition
+	Tasks    []TaskSpec `json:"tasks,omitempty" yaml:"tasks,omitempty"`       // tasks of a subflow, instead of a registry entry
+}
+
+// Spec returns the structure of tf as a FlowSpec, including its instantiated subflows, to be saved with
+// FlowSpec.Write and rebuilt by Registry.Build. Tasks keep the registry entry they were built from,
+// other tasks are expected under their own name. Subflows built from a registry entry are described
+// by the entry rather than by the tasks it instantiated.
+func (tf *TaskFlow) Spec() *FlowSpec {
+	return &FlowSpec{Name: tf.graph.name, Tasks: specOf(tf.graph)}
+}
+
+func specOf(g *eGraph) []TaskSpec {
+	specs := make([]TaskSpec, 0, len(g.nodes))
+	for _, n := range g.nodes {
+		t := TaskSpec{Name: n.name, Kind: string(n.Typ)}
+		if n.fn != "" && n.fn != n.name {
+			t.Func = n.fn
+		}
+		if n.priority != NORMAL {
+			t.Priority = n.priority.String()
+		}
+		switch p := n.ptr.(type) {
+		case *Condition:
+			for i := 0; i < len(p.mapper); i++ {
+				t.Precede = append(t.Precede, p.mapper[uint(i)].name)
+			}
+		case *Subflow:
+			// a subflow from the registry is described by its entry, whatever tasks it instantiated
+			if p.g.instantiated && n.fn == "" {
+				t.Tasks = specOf(p.g)
+			}
+		}
+		if n.Typ != nodeCondition {
+			for _, succ := range n.successors {
+				t.Precede = append(t.Precede, succ.name)
+			}
+		}
+		specs = append(specs, t)
+	}
+	return specs
+}
+
+// Write encodes spec in indented JSON, tasks in the order they were created.
+func (spec *FlowSpec) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(spec); err != nil {
+		return fmt.Errorf("encode flow spec -> %w", err)
+	}
+	return nil
+}
+
+// Diff lists the differences from spec to other: tasks added or removed and tasks whose kind, func,
+// priority or successors changed, named by their path. It is empty if both declare the same flow.
+func (spec *FlowSpec) Diff(other *FlowSpec) []string {
+	var changes []string
+	if spec.Name != other.Name {
+		changes = append(changes, fmt.Sprintf("name %q -> %q", spec.Name, other.Name))
+	}
+	return diffTasks(spec.Tasks, other.Tasks, "", changes)
+}
+
+func diffTasks(from, to []TaskSpec, prefix string, changes []string) []string {
+	index := func(specs []TaskSpec) map[string]*TaskSpec {
+		m := make(map[string]*TaskSpec, len(specs))
+		for i := range specs {
+			m[specs[i].Name] = &specs[i]
+		}
+		return m
+	}
+	old, cur := index(from), index(to)
+	normalize := func(t *TaskSpec) TaskSpec {
+		n := *t
+		if n.Kind == "" {
+			n.Kind = string(nodeStatic)
+		}
+		n.Func = specFunc(t)
+		if n.Priority == "" {
+			n.Priority = NORMAL.String()
+		}
+		return n
+	}
+
+	for _, t := range from {
+		if _, ok := cur[t.Name]; !ok {
+			changes = append(changes, fmt.Sprintf("removed task %q", prefix+t.Name))
+		}
+	}
+	for i := range to {
+		path := prefix + to[i].Name
+		prev, ok := old[to[i].Name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("added task %q", path))
+			continue
+		}
+		a, b := normalize(prev), normalize(&to[i])
+		for _, f := range [][3]string{{"kind", a.Kind, b.Kind}, {"func", a.Func, b.Func}, {"priority", a.Priority, b.Priority}} {
+			if f[1] != f[2] {
+				changes = append(changes, fmt.Sprintf("task %q: %s %s -> %s", path, f[0], f[1], f[2]))
+			}
+		}
+		if !slices.Equal(a.Precede, b.Precede) {
+			changes = append(changes, fmt.Sprintf("task %q: precedes %q -> %q", path, a.Precede, b.Precede))
+		}
+		changes = diffTasks(a.Tasks, b.Tasks, path+"/", changes)
+	}
+	return changes
+}
+
+// SpecError lists the problems found by Registry.Build.
+type SpecError struct {
+	Flow     string
+	Problems []string // one per problem, naming the tasks involved by their path, e.g. "sub/task"
+}
+
+func (e *SpecError) Error() string {
+	return fmt.Sprintf("invalid spec %q: %s", e.Flow, strings.Join(e.Problems, "; "))
+}
+
+// ParseFlowSpec decodes a FlowSpec, rejecting unknown fields. A spec holding a JSON object is read as
+// JSON, others as YAML, of which ParseFlowSpec reads the following subset:
+//   - one document, optionally started by "---" and ended by "...", indented with spaces;
+//   - block mappings and sequences, a sequence may be indented as its key;
+//   - flow mappings and sequences, which may span lines;
+//   - plain scalars, and single- or double-quoted scalars with the escapes of YAML, on one line;
+//   - comments.
+//
+// Scalars decode as strings, except null and ~. Anchors, aliases, tags, directives, block scalars,
+// multi-line scalars and multiple documents are rejected; decode such documents with a YAML package
+// into a FlowSpec, whose fields carry yaml tags.
+func ParseFlowSpec(r io.Reader) (*FlowSpec, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read flow spec -> %w", err)
+	}
+	if doc := bytes.TrimSpace(data); len(doc) > 0 && (doc[0] != '{' || !json.Valid(doc)) {
+		v, err := decodeYAML(data)
+		if err != nil {
+			return nil, fmt.Errorf("decode flow spec -> %w", err)
+		}
+		if data, err = json.Marshal(v); err != nil {
+			return nil, fmt.Errorf("decode flow spec -> %w", err)
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	spec := &FlowSpec{}
+	if err := dec.Decode(spec); err != nil {
+		return nil, fmt.Errorf("decode flow spec -> %w", err)
+	}
+	return spec, nil
+}
+
+// Registry holds the named Go functions implementing the tasks of FlowSpecs.
+// It is safe for concurrent use.
+type Registry struct {
+	mu         sync.RWMutex
+	statics    map[string]func()
+	conditions map[string]func() uint
+	subflows   map[string]func(sf *Subflow)
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		statics:    make(map[string]func()),
+		conditions: make(map[string]func() uint),
+		subflows:   make(map[string]func(sf *Subflow)),
+	}
+}
+
+func (r *Registry) register(name string, add func()) *Registry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, static := r.statics[name]
+	_, cond := r.conditions[name]
+	_, sub := r.subflows[name]
+	if static || cond || sub {
+		panic(fmt.Sprintf("%q is already registered", name))
+	}
+	add()
+	return r
+}
+
+// Static registers the function of static tasks.
+func (r *Registry) Static(name string, f func()) *Registry {
+	return r.register(name, func() { r.statics[name] = f })
+}
+
+// Condition registers the predicate of condition tasks.
+func (r *Registry) Condition(name string, predict func() uint) *Registry {
+	return r.register(name, func() { r.conditions[name] = predict })
+}
+
+// Subflow registers the instantiate func of subflow tasks declared without tasks.
+func (r *Registry) Subflow(name string, instantiate func(sf *Subflow)) *Registry {
+	return r.register(name, func() { r.subflows[name] = instantiate })
+}
+
+// Load parses a FlowSpec in JSON or in the YAML subset read by ParseFlowSpec, i.e. without anchors,
+// tags, block scalars and multi-line scalars, and builds its TaskFlow.
+func (r *Registry) Load(spec io.Reader) (*TaskFlow, error) {
+	s, err := ParseFlowSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	return r.Build(s)
+}
+
+// Build returns the TaskFlow declared by spec, or a *SpecError listing unknown task kinds and priorities,
+// functions missing from the registry and edges to unknown tasks. Subflows declared with tasks are
+// instantiated at once, so Dump and Validate see them before the first run.
+func (r *Registry) Build(spec *FlowSpec) (*TaskFlow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c := &specChecker{r: r}
+	c.check(spec.Tasks, "")
+	if len(c.problems) > 0 {
+		return nil, &SpecError{Flow: spec.Name, Problems: c.problems}
+	}
+	tf := NewTaskFlow(spec.Name)
+	r.build(tf, spec.Tasks)
+	return tf, nil
+}
+
+// taskBuilder is implemented by TaskFlow and Subflow.
+type taskBuilder interface {
+	NewTask(name string, f func()) *Task
+	NewCondition(name string, predict func() uint) *Task
+	NewSubflow(name string, instantiate func(sf *Subflow)) *Task
+}
+
+var specPriorities = map[string]TaskPriority{"": NORMAL, "high": HIGH, "normal": NORMAL, "low": LOW}
+
+func specFunc(t *TaskSpec) string {
+	if t.Func != "" {
+		return t.Func
+	}
+	return t.Name
+}
+
+// build adds the tasks of a checked spec to b.
+func (r *Registry) build(b taskBuilder, specs []TaskSpec) {
+	tasks := make(map[string]*Task, len(specs))
+	for i := range specs {
+		t := &specs[i]
+		var task *Task
+		fn := specFunc(t)
+		switch t.Kind {
+		case "", string(nodeStatic):
+			task = b.NewTask(t.Name, r.statics[fn])
+		case string(nodeCondition):
+			task = b.NewCondition(t.Name, r.conditions[fn])
+		case string(nodeSubflow):
+			if len(t.Tasks) == 0 {
+				task = b.NewSubflow(t.Name, r.subflows[fn])
+				break
+			}
+			children := t.Tasks
+			task = b.NewSubflow(t.Name, func(sf *Subflow) { r.build(sf, children) })
+			sf := task.node.ptr.(*Subflow)
+			sf.handle(sf)
+			sf.g.instantiated = true
+			fn = "" // built from its tasks rather than from a registry entry
+		}
+		task.node.fn = fn
+		tasks[t.Name] = task.Priority(specPriorities[t.Priority])
+	}
+	for _, t := range specs {
+		for _, succ := range t.Precede {
+			tasks[t.Name].Precede(tasks[succ])
+		}
+	}
+}
+
+// specChecker collects the problems of a FlowSpec.
+type specChecker struct {
+	r        *Registry
+	problems []string
+}
+
+func (c *specChecker) report(format string, args ...any) {
+	c.problems = append(c.problems, fmt.Sprintf(format, args...))
+}
+
+func (c *specChecker) check(specs []TaskSpec, prefix string) {
+	names := make(map[string]bool, len(specs))
+	for _, t := range specs {
+		if t.Name == "" {
+			c.report("task without name in %q", strings.TrimSuffix(prefix, "/"))
+			continue
+		}
+		if names[t.Name] {
+			c.report("duplicate task name %q", prefix+t.Name)
+		}
+		names[t.Name] = true
+	}
+
+	for i := range specs {
+		t := &specs[i]
+		path := prefix + t.Name
+		if _, ok := specPriorities[t.Priority]; !ok {
+			c.report("task %q: unknown priority %q", path, t.Priority)
+		}
+		if len(t.Tasks) > 0 && t.Kind != string(nodeSubflow) {
+			c.report("task %q: only subflows have tasks", path)
+		}
+		switch t.Kind {
+		case "", string(nodeStatic):
+			if _, ok := c.r.statics[specFunc(t)]; !ok {
+				c.report("task %q: static %q not in registry", path, specFunc(t))
+			}
+		case string(nodeCondition):
+			if _, ok := c.r.conditions[specFunc(t)]; !ok {
+				c.report("task %q: condition %q not in registry", path, specFunc(t))
+			}
+			if len(t.Precede) == 0 {
+				c.report("task %q: condition has no successors", path)
+			}
+		case string(nodeSubflow):
+			if len(t.Tasks) > 0 {
+				c.check(t.Tasks, path+"/")
+			} else if _, ok := c.r.subflows[specFunc(t)]; !ok {
+				c.report("task %q: subflow %q not in registry", path, specFunc(t))
+			}
+		default:
+			c.report("task %q: unknown kind %q, want static, condition or subflow", path, t.Kind)
+		}
+		for _, succ := range t.Precede {
+			if !names[succ] {
+				c.report("task %q: precedes unknown task %q", path, prefix+succ)
+			}
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package gotaskflow_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	gotaskflow "github.com/noneback/go-taskflow"
+)
+
+const pipelineSpec = `{
+  "name": "pipeline",
+  "tasks": [
+    {"name": "extract", "precede": ["check"]},
+    {"name": "check", "kind": "condition", "func": "isFull", "precede": ["transform", "skip"]},
+    {"name": "transform", "kind": "subflow", "priority": "high", "precede": ["load"], "tasks": [
+      {"name": "clean", "func": "step", "precede": ["enrich"]},
+      {"name": "enrich", "func": "step"}
+    ]},
+    {"name": "skip", "func": "step"},
+    {"name": "load", "priority": "low"}
+  ]
+}`
+
+func newPipelineRegistry(ran *[]string) *gotaskflow.Registry {
+	record := func(name string) func() {
+		return func() { *ran = append(*ran, name) }
+	}
+	return gotaskflow.NewRegistry().
+		Static("extract", record("extract")).
+		Static("load", record("load")).
+		Static("step", record("step")).
+		Condition("isFull", func() uint { return 0 })
+}
+
+func TestRegistryLoad(t *testing.T) {
+	var ran []string
+	tf, err := newPipelineRegistry(&ran).Load(strings.NewReader(pipelineSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tf.Validate(); err != nil {
+		t.Errorf("expected a valid flow, got %v", err)
+	}
+
+	var dot bytes.Buffer
+	if err := tf.Dump(&dot); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"extract", "check", "transform", "clean", "enrich", "skip", "load"} {
+		if !strings.Contains(dot.String(), want) {
+			t.Errorf("expected %q in dump:\n%s", want, dot.String())
+		}
+	}
+
+	gotaskflow.NewSimulator(1).Run(tf)
+	if strings.Join(ran, ",") != "extract,step,step,load" {
+		t.Errorf("unexpected executions %v", ran)
+	}
+}
+
+func TestRegistryBuildErrors(t *testing.T) {
+	spec := &gotaskflow.FlowSpec{
+		Name: "bad",
+		Tasks: []gotaskflow.TaskSpec{
+			{Name: "A", Kind: "loop", Precede: []string{"Z"}},
+			{Name: "B", Func: "missing", Priority: "urgent"},
+			{Name: "C", Kind: "condition", Func: "isFull"},
+			{Name: "S", Kind: "subflow", Tasks: []gotaskflow.TaskSpec{{Name: "x", Func: "step", Precede: []string{"B"}}}},
+			{Name: "B", Func: "step"},
+		},
+	}
+	_, err := newPipelineRegistry(nil).Build(spec)
+
+	var specErr *gotaskflow.SpecError
+	if !errors.As(err, &specErr) {
+		t.Fatalf("expected a SpecError, got %v", err)
+	}
+	for _, want := range []string{
+		`task "A": unknown kind "loop"`,
+		`task "A": precedes unknown task "Z"`,
+		`task "B": static "missing" not in registry`,
+		`task "B": unknown priority "urgent"`,
+		`task "C": condition has no successors`,
+		`task "S/x": precedes unknown task "S/B"`,
+		`duplicate task name "B"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
+
+const pipelineSpecYAML = `# same flow as pipelineSpec
+name: pipeline
+tasks:
+- name: extract
+  precede: [check]
+- {name: check, kind: condition, func: isFull, precede: [transform, skip]}
+- name: transform
+  kind: subflow
+  priority: "high"
+  precede:
+    - load
+  tasks:
+    - name: clean
+      func: step # shared by several tasks
+      precede: [enrich]
+    - name: enrich
+      func: 'step'
+- name: skip
+  func: step
+- name: load
+  priority: low
+`
+
+func TestParseFlowSpecYAML(t *testing.T) {
+	want, err := gotaskflow.ParseFlowSpec(strings.NewReader(pipelineSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := gotaskflow.ParseFlowSpec(strings.NewReader(pipelineSpecYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("YAML spec %+v differs from JSON spec %+v", got, want)
+	}
+
+	var ran []string
+	if _, err := newPipelineRegistry(&ran).Load(strings.NewReader(pipelineSpecYAML)); err != nil {
+		t.Errorf("load YAML spec: %v", err)
+	}
+	_, err = gotaskflow.ParseFlowSpec(strings.NewReader("name: x\ntasks:\n- name: A\n  preced: [B]\n"))
+	if err == nil || !strings.Contains(err.Error(), "preced") {
+		t.Errorf("expected an error naming the unknown field, got %v", err)
+	}
+}
+
+func TestParseFlowSpecYAMLFlowMapping(t *testing.T) {
+	got, err := gotaskflow.ParseFlowSpec(strings.NewReader(`{name: "f\/1", tasks: [
+  {name: A, precede: [B]}, # comment
+  {name: B, func: 'step'}
+]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &gotaskflow.FlowSpec{Name: "f/1", Tasks: []gotaskflow.TaskSpec{
+		{Name: "A", Precede: []string{"B"}},
+		{Name: "B", Func: "step"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got spec %+v, want %+v", got, want)
+	}
+
+	if _, err := gotaskflow.ParseFlowSpec(strings.NewReader(`{"name": "f", "tasks": [}`)); err == nil {
+		t.Error("expected an error for a malformed spec")
+	}
+}
+
+func TestParseFlowSpecUnknownField(t *testing.T) {
+	_, err := gotaskflow.ParseFlowSpec(strings.NewReader(`{"name": "x", "tasks": [{"name": "A", "preced": ["B"]}]}`))
+	if err == nil || !strings.Contains(err.Error(), "preced") {
+		t.Errorf("expected an error naming the unknown field, got %v", err)
+	}
+}
+
+func TestRegistryDuplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic registering a name twice")
+		}
+	}()
+	gotaskflow.NewRegistry().Static("A", func() {}).Condition("A", func() uint { return 0 })
+}
+
+func TestTaskFlowSpecRoundTrip(t *testing.T) {
+	var ran []string
+	reg := newPipelineRegistry(&ran)
+	tf, err := reg.Load(strings.NewReader(pipelineSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var saved bytes.Buffer
+	if err := tf.Spec().Write(&saved); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := reg.Load(bytes.NewReader(saved.Bytes()))
+	if err != nil {
+		t.Fatalf("reload %s: %v", saved.String(), err)
+	}
+	if diff := tf.Spec().Diff(reloaded.Spec()); len(diff) != 0 {
+		t.Errorf("expected the same structure after reload, got %q", diff)
+	}
+
+	var resaved bytes.Buffer
+	if err := reloaded.Spec().Write(&resaved); err != nil {
+		t.Fatal(err)
+	}
+	if saved.String() != resaved.String() {
+		t.Errorf("expected a stable encoding, got:\n%s\nwant:\n%s", resaved.String(), saved.String())
+	}
+}
+
+func TestTaskFlowSpecRegistrySubflow(t *testing.T) {
+	var ran []string
+	newRegistry := func(fetched string) *gotaskflow.Registry {
+		return newPipelineRegistry(&ran).Subflow("fetch", func(sf *gotaskflow.Subflow) {
+			sf.NewTask(fetched, func() { ran = append(ran, fetched) })
+		})
+	}
+	spec := &gotaskflow.FlowSpec{Name: "G", Tasks: []gotaskflow.TaskSpec{
+		{Name: "fetch", Kind: "subflow", Precede: []string{"mirror"}},
+		{Name: "mirror", Kind: "subflow", Func: "fetch"},
+	}}
+	tf, err := newRegistry("v1").Build(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotaskflow.NewExecutor(2).Run(tf).Wait()
+
+	saved := tf.Spec()
+	if diff := spec.Diff(saved); len(diff) != 0 || !reflect.DeepEqual(saved, spec) {
+		t.Errorf("expected the registry subflows kept after a run, got %+v, diff %q", saved, diff)
+	}
+
+	ran = nil
+	rebuilt, err := newRegistry("v2").Build(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotaskflow.NewExecutor(2).Run(rebuilt).Wait()
+	if strings.Join(ran, ",") != "v2,v2" {
+		t.Errorf("expected the edited registry entry to run, ran %q", ran)
+	}
+}
+
+func TestTaskFlowSpecInstantiated(t *testing.T) {
+	tf := gotaskflow.NewTaskFlow("G")
+	cond := tf.NewCondition("cond", func() uint { return 0 })
+	A, B := tf.NewTask("A", func() {}).Priority(gotaskflow.HIGH), tf.NewTask("B", func() {})
+	sub := tf.NewSubflow("sub", func(sf *gotaskflow.Subflow) {
+		sf.NewTask("x", func() {}).Precede(sf.NewTask("y", func() {}))
+	})
+	cond.Precede(B, A)
+	B.Precede(sub)
+
+	before := tf.Spec()
+	if len(before.Tasks[3].Tasks) != 0 {
+		t.Errorf("expected no tasks for a subflow not instantiated yet, got %+v", before.Tasks[3])
+	}
+	if strings.Join(before.Tasks[0].Precede, ",") != "B,A" || before.Tasks[1].Priority != "high" {
+		t.Errorf("unexpected spec %+v", before.Tasks)
+	}
+
+	gotaskflow.NewExecutor(2).Run(tf).Wait()
+	after := tf.Spec()
+	diff := before.Diff(after)
+	if len(diff) != 2 || diff[0] != `added task "sub/x"` || diff[1] != `added task "sub/y"` {
+		t.Errorf("expected the subflow tasks added, got %q", diff)
+	}
+
+	renamed := tf.Spec()
+	renamed.Tasks[0].Precede = []string{"A", "B"}
+	renamed.Tasks[2].Kind = "condition"
+	diff = after.Diff(renamed)
+	if len(diff) != 2 || !strings.Contains(diff[0], `task "cond": precedes ["B" "A"] -> ["A" "B"]`) || diff[1] != `task "B": kind static -> condition` {
+		t.Errorf("unexpected diff %q", diff)
+	}
+}
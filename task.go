@@ -1,5 +1,10 @@
 package gotaskflow
 
+import (
+	"fmt"
+	"slices"
+)
+
 // Basic component of Taskflow
 type Task struct {
 	node *innerNode
@@ -31,12 +36,134 @@ func (t *Task) Name() string {
 	return t.node.name
 }
 
+// ID returns the identifier of the task, unique among the tasks created by the process.
+func (t *Task) ID() uint64 {
+	return t.node.id
+}
+
+// Path returns the qualified name of the task: the names of its flow and enclosing subflows
+// followed by its own, e.g. "flow/sub/task". Traces, profiles, DOT output and Verify name tasks by path.
+func (t *Task) Path() string {
+	return t.node.path()
+}
+
+// Label attaches metadata to the task, e.g. Label("owner", "infra"); a label without value tags the task,
+// e.g. Label("db", ""). Labels are carried into TaskInfo, traces, profiles and metrics, and select tasks
+// with TaskFlow.Select and Labeled.
+func (t *Task) Label(key, value string) *Task {
+	if t.node.labels == nil {
+		t.node.labels = make(map[string]string)
+	}
+	t.node.labels[key] = value
+	return t
+}
+
+// Labels returns a copy of the labels of the task.
+func (t *Task) Labels() map[string]string {
+	labels := make(map[string]string, len(t.node.labels))
+	for k, v := range t.node.labels {
+		labels[k] = v
+	}
+	return labels
+}
+
+// HasLabel reports whether the task carries key, with one of values if any is given.
+func (t *Task) HasLabel(key string, values ...string) bool {
+	v, ok := t.node.labels[key]
+	return ok && (len(values) == 0 || slices.Contains(values, v))
+}
+
+// Labeled returns a predicate for TaskFlow.Select and NewFilterVisualizer keeping the tasks carrying key,
+// with one of values if any is given.
+func Labeled(key string, values ...string) func(*Task) bool {
+	return func(t *Task) bool {
+		return t.HasLabel(key, values...)
+	}
+}
+
+// TaskType is the kind of a task.
+type TaskType string
+
+const (
+	TaskStatic    = TaskType(nodeStatic)
+	TaskCondition = TaskType(nodeCondition)
+	TaskSubflow   = TaskType(nodeSubflow)
+)
+
+// Type returns the kind of the task.
+func (t *Task) Type() TaskType {
+	return TaskType(t.node.Typ)
+}
+
+// Successors returns the tasks depending on t, for a condition task in branch order.
+func (t *Task) Successors() []*Task {
+	if cond, ok := t.node.ptr.(*Condition); ok {
+		succs := make([]*Task, len(cond.mapper))
+		for i := range succs {
+			succs[i] = cond.mapper[uint(i)].task
+		}
+		return succs
+	}
+	return handlesOf(t.node.successors)
+}
+
+// Predecessors returns the tasks t depends on.
+func (t *Task) Predecessors() []*Task {
+	return handlesOf(t.node.dependents)
+}
+
+// Tasks returns the tasks of a subflow once instantiated, nil otherwise.
+func (t *Task) Tasks() []*Task {
+	if sf, ok := t.node.ptr.(*Subflow); ok && sf.g.instantiated {
+		return handlesOf(sf.g.nodes)
+	}
+	return nil
+}
+
+// Rebuild makes a subflow instantiate its tasks again on every execution, discarding the tasks of the
+// previous one, for subflows built from runtime data such as one task per file found. Handles to the
+// discarded tasks are detached from the flow. It panics if the task is not a subflow.
+func (t *Task) Rebuild() *Task {
+	sf, ok := t.node.ptr.(*Subflow)
+	if !ok {
+		panic(fmt.Sprintf("task %q is not a subflow", t.Name()))
+	}
+	sf.rebuild = true
+	return t
+}
+
+// Parent returns the subflow task t belongs to, nil for a task of a TaskFlow.
+func (t *Task) Parent() *Task {
+	if t.node.g == nil || t.node.g.parent == nil {
+		return nil
+	}
+	return t.node.g.parent.task
+}
+
+// handlesOf returns the Task handles of nodes.
+func handlesOf(nodes []*innerNode) []*Task {
+	tasks := make([]*Task, len(nodes))
+	for i, n := range nodes {
+		tasks[i] = n.task
+	}
+	return tasks
+}
+
 // Priority sets task's sche priority. Noted that due to goroutine concurrent mode, it can only assure task schedule priority, rather than its execution.
 func (t *Task) Priority(p TaskPriority) *Task {
 	t.node.priority = p
 	return t
 }
 
+// Snapshot sets how the task's output is captured and restored. When the run is checkpointed,
+// save is called after the task finishes and its result is stored along with the completion record;
+// on Resume, restore receives that result instead of the task running again.
+func (t *Task) Snapshot(save func() ([]byte, error), restore func([]byte) error) *Task {
+	t.node.snapshot = save
+	t.node.restore = restore
+	return t
+}
+
 // Task sche priority
 type TaskPriority uint
 
@@ -45,3 +172,16 @@ const (
 	NORMAL
 	LOW
 )
+
+func (p TaskPriority) String() string {
+	switch p {
+	case HIGH:
+		return "high"
+	case NORMAL:
+		return "normal"
+	case LOW:
+		return "low"
+	default:
+		return "unknown"
+	}
+}
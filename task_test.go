@@ -0,0 +1,163 @@
+package gotaskflow_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	gotaskflow "github.com/noneback/go-taskflow"
+)
+
+func names(tasks []*gotaskflow.Task) string {
+	s := make([]string, len(tasks))
+	for i, t := range tasks {
+		s[i] = t.Name()
+	}
+	return strings.Join(s, ",")
+}
+
+func newIntrospectionFlow() *gotaskflow.TaskFlow {
+	tf := gotaskflow.NewTaskFlow("G")
+	init := tf.NewTask("init", func() {})
+	loop := tf.NewCondition("loop", func() uint { return 1 })
+	body := tf.NewCondition("body", func() uint { return 0 })
+	sub := tf.NewSubflow("sub", func(sf *gotaskflow.Subflow) {
+		y := sf.NewTask("y", func() {})
+		x := sf.NewTask("x", func() {})
+		x.Precede(y)
+	})
+	init.Precede(loop)
+	loop.Precede(body, sub)
+	body.Precede(loop)
+	return tf
+}
+
+func TestTaskIntrospection(t *testing.T) {
+	tf := newIntrospectionFlow()
+	if got := names(tf.Tasks()); got != "init,loop,body,sub" {
+		t.Errorf("unexpected tasks %s", got)
+	}
+
+	loop := tf.FindTask("loop")
+	if loop == nil || loop.Type() != gotaskflow.TaskCondition {
+		t.Fatalf("expected condition loop, got %v", loop)
+	}
+	if got := names(loop.Successors()); got != "body,sub" {
+		t.Errorf("expected successors in branch order, got %s", got)
+	}
+	if got := names(loop.Predecessors()); got != "init,body" {
+		t.Errorf("unexpected predecessors %s", got)
+	}
+	if loop.Successors()[0] != tf.FindTask("body") {
+		t.Error("expected the same handle for the same task")
+	}
+
+	sub := tf.FindTask("sub")
+	if sub.Type() != gotaskflow.TaskSubflow || sub.Tasks() != nil || tf.FindTask("x") != nil {
+		t.Error("expected no tasks for a subflow not instantiated yet")
+	}
+	gotaskflow.NewExecutor(2).Run(tf).Wait()
+
+	if got := names(sub.Tasks()); got != "y,x" {
+		t.Errorf("unexpected subflow tasks %s", got)
+	}
+	x := tf.FindTask("x")
+	if x == nil || x.Parent() != sub || x.Type() != gotaskflow.TaskStatic || sub.Parent() != nil {
+		t.Errorf("expected x in sub, got %v", x)
+	}
+}
+
+func TestTopologicalOrder(t *testing.T) {
+	tf := newIntrospectionFlow()
+	gotaskflow.NewExecutor(2).Run(tf).Wait()
+
+	order, err := tf.TopologicalOrder()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(order); got != "init,loop,sub,x,y,body" {
+		t.Errorf("unexpected order %s", got)
+	}
+
+	cyclic := gotaskflow.NewTaskFlow("cyclic")
+	A, B, C := cyclic.NewTask("A", func() {}), cyclic.NewTask("B", func() {}), cyclic.NewTask("C", func() {})
+	A.Precede(B)
+	B.Precede(C)
+	C.Precede(B)
+	if _, err := cyclic.TopologicalOrder(); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("expected a cycle error, got %v", err)
+	}
+}
+
+func TestTaskIdentity(t *testing.T) {
+	tf := gotaskflow.NewTaskFlow("G")
+	A, unnamed := tf.NewTask("A", func() {}), tf.NewTask("", func() {})
+	if A.ID() == 0 || A.ID() == unnamed.ID() || unnamed.Name() == "" {
+		t.Errorf("expected distinct IDs and a default name, got %d %d %q", A.ID(), unnamed.ID(), unnamed.Name())
+	}
+	tf.NewSubflow("sub", func(sf *gotaskflow.Subflow) {
+		sf.NewSubflow("inner", func(sf *gotaskflow.Subflow) {
+			sf.NewTask("A", func() {})
+		})
+	})
+	gotaskflow.NewExecutor(4).Run(tf).Wait()
+
+	inner := tf.FindTask("inner").Tasks()[0]
+	if A.Path() != "G/A" || inner.Path() != "G/sub/inner/A" {
+		t.Errorf("unexpected paths %q, %q", A.Path(), inner.Path())
+	}
+}
+
+func TestRequireUniqueNames(t *testing.T) {
+	expectPanic := func(name string, f func()) {
+		t.Helper()
+		defer func() {
+			if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "duplicate task name") {
+				t.Errorf("%s: expected duplicate name panic, got %v", name, r)
+			}
+		}()
+		f()
+	}
+
+	tf := gotaskflow.NewTaskFlow("G").RequireUniqueNames()
+	tf.NewTask("A", func() {})
+	expectPanic("flow", func() { tf.NewTask("A", func() {}) })
+
+	ran := false
+	tf.NewSubflow("sub", func(sf *gotaskflow.Subflow) {
+		sf.NewTask("A", func() {}) // other graph level
+		expectPanic("subflow", func() { sf.NewTask("A", func() {}) })
+		ran = true
+	})
+	gotaskflow.NewExecutor(2).Run(tf).Wait()
+	if !ran {
+		t.Error("expected subflow to run")
+	}
+
+	dup := gotaskflow.NewTaskFlow("dup")
+	dup.NewTask("A", func() {})
+	dup.NewTask("A", func() {})
+	expectPanic("existing", func() { dup.RequireUniqueNames() })
+}
+
+func TestTaskLabels(t *testing.T) {
+	tf := gotaskflow.NewTaskFlow("G")
+	A := tf.NewTask("A", func() {}).Label("owner", "infra").Label("db", "")
+	tf.NewTask("B", func() {}).Label("owner", "web")
+	tf.NewSubflow("sub", func(sf *gotaskflow.Subflow) {
+		sf.NewTask("C", func() {}).Label("owner", "infra")
+	})
+	gotaskflow.NewExecutor(4).Run(tf).Wait()
+
+	labels := A.Labels()
+	labels["owner"] = "changed"
+	if !A.HasLabel("owner", "infra") || !A.HasLabel("db") || A.HasLabel("owner", "web") || A.HasLabel("ticket") {
+		t.Errorf("unexpected labels %v", A.Labels())
+	}
+	if got := names(tf.Select(gotaskflow.Labeled("owner", "infra"))); got != "A,C" {
+		t.Errorf("unexpected selection %s", got)
+	}
+	if got := names(tf.Select(gotaskflow.Labeled("db"))); got != "A" {
+		t.Errorf("unexpected selection %s", got)
+	}
+}
@@ -1,6 +1,7 @@
 package gotaskflow
 
 import (
+	"fmt"
 	"io"
 )
 
@@ -10,12 +11,49 @@ type TaskFlow struct {
 	frozen bool
 }
 
-// Reset resets taskflow
+// Reset restores the taskflow to its state before its first run, so that it can be edited and run again:
+// canceled graphs are restored and subflows discard their tasks, instantiated again by the next run.
 func (tf *TaskFlow) Reset() {
-	// tf.graph.reset()
+	tf.graph.restore()
 	tf.frozen = false
 }
 
+// Clear removes every task of the taskflow, which can be built again.
+func (tf *TaskFlow) Clear() {
+	tf.graph.clear()
+	tf.frozen = false
+}
+
+// RemoveTask removes task, from the taskflow or one of its subflows, and its edges. The branches of
+// a condition preceding task are renumbered: the ones after task shift down by one.
+func (tf *TaskFlow) RemoveTask(task *Task) {
+	tf.mutable(task)
+	task.node.g.remove(task.node)
+}
+
+// RemoveEdge removes the edges from one task to another, renumbering the branches of from if it is a condition.
+func (tf *TaskFlow) RemoveEdge(from, to *Task) {
+	tf.mutable(from)
+	tf.mutable(to)
+	from.node.successors = removeNode(from.node.successors, to.node)
+	to.node.dependents = removeNode(to.node.dependents, from.node)
+	from.node.remap()
+}
+
+// mutable panics unless task belongs to the taskflow and the taskflow can be edited.
+func (tf *TaskFlow) mutable(task *Task) {
+	if tf.frozen {
+		panic("Taskflow is frozen, cannot remove tasks or edges, Reset it first")
+	}
+	g := task.node.g
+	for g != nil && g != tf.graph && g.parent != nil {
+		g = g.parent.g
+	}
+	if g != tf.graph {
+		panic(fmt.Sprintf("task %q is not in taskflow %q", task.Name(), tf.Name()))
+	}
+}
+
 // NewTaskFlow returns a taskflow struct
 func NewTaskFlow(name string) *TaskFlow {
 	return &TaskFlow{
@@ -30,6 +68,7 @@ func (tf *TaskFlow) push(tasks ...*Task) {
 	}
 
 	for _, task := range tasks {
+		task.node.task = task
 		tf.graph.push(task.node)
 	}
 }
@@ -38,6 +77,119 @@ func (tf *TaskFlow) Name() string {
 	return tf.graph.name
 }
 
+// RequireUniqueNames makes creating a task named like another task of the same flow or subflow panic,
+// so that every task has its own path. Tasks of different subflows may still share a name.
+// It panics if the taskflow already has such duplicates.
+func (tf *TaskFlow) RequireUniqueNames() *TaskFlow {
+	tf.graph.walk(func(n *innerNode) {
+		if first := n.g.find(n.name); first != n {
+			panic(fmt.Sprintf("duplicate task name %q in %q", n.name, n.g.path()))
+		}
+	})
+	tf.graph.unique = true
+	return tf
+}
+
+// Tasks returns the tasks of the taskflow in creation order, without the tasks of its subflows.
+func (tf *TaskFlow) Tasks() []*Task {
+	return handlesOf(tf.graph.nodes)
+}
+
+// FindTask returns the first task named name, searching the taskflow and then its instantiated
+// subflows breadth first, or nil if there is none.
+func (tf *TaskFlow) FindTask(name string) *Task {
+	for level := []*eGraph{tf.graph}; len(level) > 0; {
+		var next []*eGraph
+		for _, g := range level {
+			for _, n := range g.nodes {
+				if n.name == name {
+					return n.task
+				}
+				if sf, ok := n.ptr.(*Subflow); ok && sf.g.instantiated {
+					next = append(next, sf.g)
+				}
+			}
+		}
+		level = next
+	}
+	return nil
+}
+
+// Select returns the tasks of the taskflow and of its instantiated subflows for which keep returns true,
+// each subflow followed by its own tasks, e.g. tf.Select(Labeled("io")).
+func (tf *TaskFlow) Select(keep func(*Task) bool) []*Task {
+	var tasks []*Task
+	tf.graph.walk(func(n *innerNode) {
+		if keep(n.task) {
+			tasks = append(tasks, n.task)
+		}
+	})
+	return tasks
+}
+
+// TopologicalOrder returns the tasks of the taskflow ordered so that every task comes after its
+// predecessors, each instantiated subflow followed by its own tasks in topological order.
+// Edges closing a loop through a condition task are ignored; other cycles are reported as an error.
+func (tf *TaskFlow) TopologicalOrder() ([]*Task, error) {
+	var order []*Task
+	if err := topologicalOrder(tf.graph, &order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+func topologicalOrder(g *eGraph, order *[]*Task) error {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[*innerNode]int, len(g.nodes))
+	post := make([]*innerNode, 0, len(g.nodes))
+	var visit func(n *innerNode) error
+	visit = func(n *innerNode) error {
+		state[n] = visiting
+		for _, succ := range n.successors {
+			if succ.g != g {
+				continue
+			}
+			switch state[succ] {
+			case 0:
+				if err := visit(succ); err != nil {
+					return err
+				}
+			case visiting:
+				if n.Typ != nodeCondition {
+					return fmt.Errorf("cycle without a condition task through %q and %q", n.name, succ.name)
+				}
+			}
+		}
+		state[n] = visited
+		post = append(post, n)
+		return nil
+	}
+	// visit entries first, so that loops are entered where the flow enters them
+	for _, entries := range [2]bool{true, false} {
+		for _, n := range g.nodes {
+			if state[n] == 0 && (len(n.dependents) == 0) == entries {
+				if err := visit(n); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for i := len(post) - 1; i >= 0; i-- {
+		n := post[i]
+		*order = append(*order, n.task)
+		if sf, ok := n.ptr.(*Subflow); ok && sf.g.instantiated {
+			if err := topologicalOrder(sf.g, order); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // NewStaticTask returns a attached static task
 func (tf *TaskFlow) NewTask(name string, f func()) *Task {
 	task := &Task{
@@ -47,8 +199,17 @@ func (tf *TaskFlow) NewTask(name string, f func()) *Task {
 	return task
 }
 
+// NewRuntimeTask returns a attached static task receiving the Runtime of its executor, see Runtime.
+func (tf *TaskFlow) NewRuntimeTask(name string, f func(rt *Runtime)) *Task {
+	task := &Task{
+		node: builder.NewRuntimeStatic(name, f),
+	}
+	tf.push(task)
+	return task
+}
+
 // NewSubflow returns a attached subflow task
-// NOTICE: instantiate will be invoke only once to instantiate itself
+// NOTICE: instantiate will be invoke only once to instantiate itself, unless the subflow is rebuilt, see Task.Rebuild
 func (tf *TaskFlow) NewSubflow(name string, instantiate func(sf *Subflow)) *Task {
 	task := &Task{
 		node: builder.NewSubflow(name, instantiate),
@@ -70,3 +231,8 @@ func (tf *TaskFlow) NewCondition(name string, predict func() uint) *Task {
 func (tf *TaskFlow) Dump(writer io.Writer) error {
 	return dot.Visualize(tf, writer)
 }
+
+// DumpWith writes the graph rendered by v into writer
+func (tf *TaskFlow) DumpWith(v Visualizer, writer io.Writer) error {
+	return v.Visualize(tf, writer)
+}
@@ -446,6 +446,111 @@ func TestTaskflowFrozen(t *testing.T) {
 	})
 }
 
+func TestTaskflowRemoveTask(t *testing.T) {
+	executor := gotaskflow.NewExecutor(4)
+	tf := gotaskflow.NewTaskFlow("G")
+	var ran []string
+	record := func(name string) func() {
+		return func() { ran = append(ran, name) }
+	}
+
+	cond := tf.NewCondition("cond", func() uint { return 1 })
+	A, B, C := tf.NewTask("A", record("A")), tf.NewTask("B", record("B")), tf.NewTask("C", record("C"))
+	cond.Precede(A, B, C)
+	executor.Run(tf).Wait()
+
+	utils.AssertPanics(t, "frozen", func() {
+		tf.RemoveTask(B)
+	})
+	tf.Reset()
+	tf.RemoveTask(B)
+
+	if len(tf.Tasks()) != 3 || len(B.Predecessors()) != 0 {
+		t.Errorf("expected B removed, got tasks %d", len(tf.Tasks()))
+	}
+	if succs := cond.Successors(); len(succs) != 2 || succs[1] != C {
+		t.Errorf("expected branches renumbered, got %d successors", len(succs))
+	}
+	executor.Run(tf).Wait()
+	if len(ran) != 2 || ran[0] != "B" || ran[1] != "C" {
+		t.Errorf("expected B then C, got %v", ran)
+	}
+
+	other := gotaskflow.NewTaskFlow("other")
+	utils.AssertPanics(t, "other flow", func() {
+		other.RemoveTask(A)
+	})
+}
+
+func TestTaskflowRemoveEdge(t *testing.T) {
+	executor := gotaskflow.NewExecutor(4)
+	tf := gotaskflow.NewTaskFlow("G")
+	var order []string
+	A := tf.NewTask("A", func() { order = append(order, "A") })
+	B := tf.NewTask("B", func() { order = append(order, "B") })
+	A.Precede(B)
+	tf.RemoveEdge(A, B)
+
+	if len(A.Successors()) != 0 || len(B.Predecessors()) != 0 {
+		t.Error("expected the edge removed")
+	}
+	executor.Run(tf).Wait()
+	if len(order) != 2 {
+		t.Errorf("expected both tasks to run as entries, got %v", order)
+	}
+}
+
+func TestTaskflowResetAfterPanic(t *testing.T) {
+	executor := gotaskflow.NewExecutor(4)
+	tf := gotaskflow.NewTaskFlow("G")
+	var instantiated, ran atomic.Int32
+	fail := true
+	A := tf.NewTask("A", func() {
+		if fail {
+			panic("first run fails")
+		}
+	})
+	sub := tf.NewSubflow("sub", func(sf *gotaskflow.Subflow) {
+		instantiated.Add(1)
+		sf.NewTask("x", func() { ran.Add(1) })
+	})
+	A.Precede(sub)
+
+	executor.Run(tf).Wait()
+	if ran.Load() != 0 {
+		t.Fatalf("expected nothing to run after the panic, got %d", ran.Load())
+	}
+
+	fail = false
+	tf.Reset()
+	executor.Run(tf).Wait()
+	tf.Reset()
+	executor.Run(tf).Wait()
+	if ran.Load() != 2 || instantiated.Load() != 2 || len(sub.Tasks()) != 1 {
+		t.Errorf("expected the subflow instantiated again by each run after Reset, got %d runs, %d instantiations",
+			ran.Load(), instantiated.Load())
+	}
+}
+
+func TestTaskflowClear(t *testing.T) {
+	executor := gotaskflow.NewExecutor(4)
+	tf := gotaskflow.NewTaskFlow("G")
+	A := tf.NewTask("A", func() {})
+	A.Precede(tf.NewTask("B", func() {}))
+	executor.Run(tf).Wait()
+
+	tf.Clear()
+	if len(tf.Tasks()) != 0 || len(A.Successors()) != 0 {
+		t.Error("expected no task left")
+	}
+	var ran atomic.Int32
+	tf.NewTask("C", func() { ran.Add(1) })
+	executor.Run(tf).Wait()
+	if ran.Load() != 1 {
+		t.Errorf("expected the rebuilt flow to run, got %d", ran.Load())
+	}
+}
+
 // =============================================================================
 // Stress Tests
 // =============================================================================
@@ -0,0 +1,79 @@
+// Package taskflowtest provides test assertions checking the run of a TaskFlow against its graph.
+//
+// The executor must be created with gotaskflow.WithTracer and must have run the TaskFlow once:
+//
+//	exec := gotaskflow.NewExecutor(4, gotaskflow.WithTracer())
+//	exec.Run(tf).Wait()
+//	taskflowtest.AssertExecutedInOrder(t, exec, tf)
+//	taskflowtest.AssertSkipped(t, exec, tf, "fallback")
+//	taskflowtest.AssertRanExactly(t, exec, tf, "retry", 3)
+//
+// Tasks are named by their path below the flow, e.g. "sub/task" for a task of subflow "sub".
+package taskflowtest
+
+import (
+	"bytes"
+	"testing"
+
+	gotaskflow "github.com/noneback/go-taskflow"
+)
+
+// Report returns the report of gotaskflow.Verify for the run of tf traced by exec, failing t
+// if exec has no trace.
+func Report(t testing.TB, exec gotaskflow.Executor, tf *gotaskflow.TaskFlow) *gotaskflow.ExecutionReport {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := exec.Trace(&buf); err != nil {
+		t.Fatalf("taskflowtest: trace %q: %v", tf.Name(), err)
+		return nil
+	}
+	if buf.Len() == 0 {
+		t.Fatalf("taskflowtest: no trace of %q, create the executor with gotaskflow.WithTracer()", tf.Name())
+		return nil
+	}
+	r, err := gotaskflow.Verify(&buf, tf)
+	if err != nil {
+		t.Fatalf("taskflowtest: verify %q: %v", tf.Name(), err)
+		return nil
+	}
+	return r
+}
+
+// AssertExecutedInOrder checks that every task of tf ran after its predecessors, and that no task
+// is missing from the run unless a condition skipped it.
+func AssertExecutedInOrder(t testing.TB, exec gotaskflow.Executor, tf *gotaskflow.TaskFlow) {
+	t.Helper()
+	if r := Report(t, exec, tf); r != nil && !r.OK() {
+		t.Errorf("taskflowtest: %q: %s", tf.Name(), r)
+	}
+}
+
+// AssertSkipped checks that tasks did not run since a condition took another branch.
+func AssertSkipped(t testing.TB, exec gotaskflow.Executor, tf *gotaskflow.TaskFlow, tasks ...string) {
+	t.Helper()
+	r := Report(t, exec, tf)
+	if r == nil {
+		return
+	}
+	skipped := make(map[string]bool, len(r.Skipped))
+	for _, name := range r.Skipped {
+		skipped[name] = true
+	}
+	for _, name := range tasks {
+		if !skipped[name] {
+			t.Errorf("taskflowtest: %q: task %q not skipped, ran %d times", tf.Name(), name, r.Runs[name])
+		}
+	}
+}
+
+// AssertRanExactly checks that task ran n times, as the tasks of cyclic flows may.
+func AssertRanExactly(t testing.TB, exec gotaskflow.Executor, tf *gotaskflow.TaskFlow, task string, n int) {
+	t.Helper()
+	r := Report(t, exec, tf)
+	if r == nil {
+		return
+	}
+	if got := r.Runs[task]; got != n {
+		t.Errorf("taskflowtest: %q: task %q ran %d times, want %d", tf.Name(), task, got, n)
+	}
+}
@@ -0,0 +1,128 @@
+package taskflowtest_test
+
+import (
+	"fmt"
+	"testing"
+
+	gotaskflow "github.com/noneback/go-taskflow"
+	"github.com/noneback/go-taskflow/taskflowtest"
+)
+
+// recorder records the failures of assertions instead of failing the test.
+type recorder struct {
+	testing.TB
+	errors []string
+	fatal  bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	r.fatal = true
+}
+
+// newLoop returns a flow running body n times.
+func newLoop(n int) *gotaskflow.TaskFlow {
+	tf := gotaskflow.NewTaskFlow("loop")
+	i := 0
+	init := tf.NewTask("init", func() { i = 0 })
+	loop := tf.NewCondition("loop", func() uint {
+		if i < n {
+			return 0
+		}
+		return 1
+	})
+	body := tf.NewCondition("body", func() uint { i++; return 0 })
+	done := tf.NewTask("done", func() {})
+	init.Precede(loop)
+	loop.Precede(body, done)
+	body.Precede(loop)
+	return tf
+}
+
+func TestAssertExecutedInOrder(t *testing.T) {
+	exec := gotaskflow.NewExecutor(4, gotaskflow.WithTracer())
+	tf := gotaskflow.NewTaskFlow("pipeline")
+	A, B, C := tf.NewTask("A", func() {}), tf.NewTask("B", func() {}), tf.NewTask("C", func() {})
+	sub := tf.NewSubflow("sub", func(sf *gotaskflow.Subflow) {
+		X, Y := sf.NewTask("X", func() {}), sf.NewTask("Y", func() {})
+		X.Precede(Y)
+	})
+	A.Precede(B, C)
+	B.Precede(sub)
+	C.Precede(sub)
+	exec.Run(tf).Wait()
+
+	taskflowtest.AssertExecutedInOrder(t, exec, tf)
+	taskflowtest.AssertRanExactly(t, exec, tf, "sub/Y", 1)
+}
+
+func TestAssertSkipped(t *testing.T) {
+	exec := gotaskflow.NewExecutor(4, gotaskflow.WithTracer())
+	tf := gotaskflow.NewTaskFlow("branch")
+	cond := tf.NewCondition("cond", func() uint { return 1 })
+	A, B := tf.NewTask("A", func() {}), tf.NewTask("B", func() {})
+	after := tf.NewTask("after", func() {})
+	cond.Precede(A, B)
+	A.Precede(after)
+	exec.Run(tf).Wait()
+
+	taskflowtest.AssertExecutedInOrder(t, exec, tf)
+	taskflowtest.AssertSkipped(t, exec, tf, "A", "after")
+
+	r := &recorder{TB: t}
+	taskflowtest.AssertSkipped(r, exec, tf, "B")
+	if len(r.errors) != 1 {
+		t.Errorf("expected B reported as not skipped, got %q", r.errors)
+	}
+}
+
+func TestAssertRanExactlyCycle(t *testing.T) {
+	exec := gotaskflow.NewExecutor(4, gotaskflow.WithTracer())
+	tf := newLoop(3)
+	exec.Run(tf).Wait()
+
+	taskflowtest.AssertExecutedInOrder(t, exec, tf)
+	taskflowtest.AssertRanExactly(t, exec, tf, "body", 3)
+	taskflowtest.AssertRanExactly(t, exec, tf, "loop", 4)
+	taskflowtest.AssertRanExactly(t, exec, tf, "done", 1)
+
+	r := &recorder{TB: t}
+	taskflowtest.AssertRanExactly(r, exec, tf, "body", 2)
+	if len(r.errors) != 1 {
+		t.Errorf("expected a failure for 2 runs of body, got %q", r.errors)
+	}
+}
+
+func TestAssertExecutedInOrderMissing(t *testing.T) {
+	exec := gotaskflow.NewExecutor(4, gotaskflow.WithTracer())
+	ran := gotaskflow.NewTaskFlow("flow")
+	ran.NewTask("A", func() {})
+	exec.Run(ran).Wait()
+
+	expected := gotaskflow.NewTaskFlow("flow")
+	A, B := expected.NewTask("A", func() {}), expected.NewTask("B", func() {})
+	A.Precede(B)
+
+	r := &recorder{TB: t}
+	taskflowtest.AssertExecutedInOrder(r, exec, expected)
+	if len(r.errors) != 1 || r.fatal {
+		t.Errorf("expected B reported missing, got %q", r.errors)
+	}
+}
+
+func TestReportWithoutTracer(t *testing.T) {
+	exec := gotaskflow.NewExecutor(4)
+	tf := newLoop(1)
+	exec.Run(tf).Wait()
+
+	r := &recorder{TB: t}
+	if taskflowtest.Report(r, exec, tf) != nil || !r.fatal {
+		t.Errorf("expected a fatal failure without tracer, got %q", r.errors)
+	}
+}
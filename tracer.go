@@ -1,8 +1,12 @@
 package gotaskflow
 
 import (
+	"cmp"
 	"encoding/json"
+	"fmt"
 	"io"
+	"slices"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -11,7 +15,9 @@ import (
 // tracer records task execution events and exports them in Chrome Trace Event Format.
 // The output can be visualized in Chrome's chrome://tracing or Perfetto UI (https://ui.perfetto.dev).
 type tracer struct {
+	NopObserver
 	events []chromeTraceEvent
+	execs  []traceExec // task execution of each event
 	mu     sync.Mutex
 	start  time.Time
 	tidGen atomic.Int64
@@ -26,9 +32,18 @@ type chromeTraceEvent struct {
 	Dur  int64             `json:"dur"`
 	Pid  int               `json:"pid"`
 	Tid  int64             `json:"tid"`
+	ID   int               `json:"id,omitempty"` // binds the start and finish of a flow event
+	Bp   string            `json:"bp,omitempty"`
+	S    string            `json:"s,omitempty"` // scope of an instant event
 	Args map[string]string `json:"args,omitempty"`
 }
 
+// labelArgPrefix prefixes the task labels in the args of trace events.
+const labelArgPrefix = "label."
+
+// workerTidBase separates worker rows from the rows of events without a known worker.
+const workerTidBase = 1 << 20
+
 func newTracer() *tracer {
 	return &tracer{
 		events: make([]chromeTraceEvent, 0, 64),
@@ -36,6 +51,11 @@ func newTracer() *tracer {
 	}
 }
 
+// OnTaskEnd records an event for every task execution.
+func (t *tracer) OnTaskEnd(info *TaskInfo, outcome TaskOutcome) {
+	t.AddEvent(spanOf(info, outcome))
+}
+
 // AddEvent records a task execution event from the given span.
 func (t *tracer) AddEvent(s *span) {
 	t.mu.Lock()
@@ -48,11 +68,18 @@ func (t *tracer) AddEvent(s *span) {
 		Ts:   s.begin.Sub(t.start).Microseconds(),
 		Dur:  s.cost.Microseconds(),
 		Pid:  0,
-		Tid:  t.tidGen.Add(1),
+	}
+	if s.info != nil && s.info.Worker > 0 {
+		ev.Tid = int64(s.info.Worker)
+	} else {
+		ev.Tid = workerTidBase + t.tidGen.Add(1)
 	}
 
 	// Build args with optional parent and dependents
 	args := make(map[string]string)
+	if s.extra.path != "" {
+		args["path"] = s.extra.path
+	}
 	if s.parent != nil {
 		args["parent"] = s.parent.extra.name
 	}
@@ -67,20 +94,136 @@ func (t *tracer) AddEvent(s *span) {
 		}
 		args["dependents"] = deps
 	}
+	if s.outcome != TaskFinished {
+		args["outcome"] = s.outcome.String()
+	}
+	if s.info != nil && s.info.Iteration > 0 {
+		args["iteration"] = strconv.Itoa(s.info.Iteration)
+	}
+	if s.info != nil {
+		for k, v := range s.info.Labels {
+			args[labelArgPrefix+k] = v
+		}
+	}
 	if len(args) > 0 {
 		ev.Args = args
 	}
 
 	t.events = append(t.events, ev)
+	t.execs = append(t.execs, traceExec{info: s.info, outcome: s.outcome})
+}
+
+// traceExec is the task execution behind a recorded event.
+type traceExec struct {
+	info    *TaskInfo // nil if unknown
+	outcome TaskOutcome
 }
 
 func (t *tracer) draw(w io.Writer) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
+	events := append(t.metadata(), t.events...)
+	events = append(events, t.flows()...)
+	events = append(events, t.branches()...)
+
 	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "  ")
-	return encoder.Encode(t.events)
+	return encoder.Encode(events)
+}
+
+// metadata names the process and the row of every worker.
+func (t *tracer) metadata() []chromeTraceEvent {
+	events := []chromeTraceEvent{{
+		Name: "process_name",
+		Ph:   "M",
+		Args: map[string]string{"name": "go-taskflow"},
+	}}
+	seen := make(map[int64]bool)
+	for _, ev := range t.events {
+		if ev.Tid >= workerTidBase || seen[ev.Tid] {
+			continue
+		}
+		seen[ev.Tid] = true
+		events = append(events, chromeTraceEvent{
+			Name: "thread_name",
+			Ph:   "M",
+			Tid:  ev.Tid,
+			Args: map[string]string{"name": fmt.Sprintf("worker %d", ev.Tid)},
+		})
+	}
+	slices.SortFunc(events[1:], func(a, b chromeTraceEvent) int {
+		return cmp.Compare(a.Tid, b.Tid)
+	})
+	return events
+}
+
+// flowKey identifies a task by name within one execution of its enclosing graph.
+type flowKey struct {
+	run    *RunInfo
+	parent *TaskInfo
+	name   string
+}
+
+// flows draws an arrow from the latest execution of each predecessor to every task execution.
+func (t *tracer) flows() []chromeTraceEvent {
+	execs := make(map[flowKey][]int)
+	for i, ex := range t.execs {
+		if info := ex.info; info != nil {
+			k := flowKey{run: info.Run, parent: info.Parent, name: info.Name}
+			execs[k] = append(execs[k], i)
+		}
+	}
+
+	var events []chromeTraceEvent
+	for i, ex := range t.execs {
+		info := ex.info
+		if info == nil {
+			continue
+		}
+		succ := t.events[i]
+		for _, dep := range info.Dependents {
+			pred := -1
+			for _, j := range execs[flowKey{run: info.Run, parent: info.Parent, name: dep}] {
+				end := t.events[j].Ts + t.events[j].Dur
+				if end <= succ.Ts && (pred < 0 || end > t.events[pred].Ts+t.events[pred].Dur) {
+					pred = j
+				}
+			}
+			if pred < 0 {
+				continue
+			}
+			id := len(events)/2 + 1
+			name := dep + " -> " + info.Name
+			events = append(events,
+				chromeTraceEvent{Name: name, Cat: "dependency", Ph: "s", Ts: t.events[pred].Ts + t.events[pred].Dur, Tid: t.events[pred].Tid, ID: id},
+				chromeTraceEvent{Name: name, Cat: "dependency", Ph: "f", Ts: succ.Ts, Tid: succ.Tid, ID: id, Bp: "e"},
+			)
+		}
+	}
+	return events
+}
+
+// branches marks the branch taken by every finished condition task.
+func (t *tracer) branches() []chromeTraceEvent {
+	var events []chromeTraceEvent
+	for i, ex := range t.execs {
+		info := ex.info
+		if info == nil || info.Type != string(nodeCondition) || (ex.outcome != TaskFinished && ex.outcome != TaskRestored) {
+			continue
+		}
+		ev := t.events[i]
+		events = append(events, chromeTraceEvent{
+			Name: ev.Name,
+			Cat:  "branch",
+			Ph:   "i",
+			Ts:   ev.Ts + ev.Dur,
+			Tid:  ev.Tid,
+			S:    "t",
+			Args: map[string]string{"branch": strconv.FormatUint(uint64(info.Choice), 10)},
+		})
+	}
+	return events
 }
 
 // traceRecord is an immutable snapshot of task execution events produced by a tracer.
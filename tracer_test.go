@@ -75,8 +75,11 @@ func TestTracerDraw(t *testing.T) {
 	if err := json.Unmarshal(buf.Bytes(), &events); err != nil {
 		t.Fatalf("output is not valid JSON: %v", err)
 	}
-	if len(events) != 1 {
-		t.Fatalf("expected 1 event in output, got %d", len(events))
+	if len(events) != 2 {
+		t.Fatalf("expected process metadata and 1 event in output, got %d", len(events))
+	}
+	if events[0].Ph != "M" || events[0].Name != "process_name" {
+		t.Errorf("expected process_name metadata first, got %+v", events[0])
 	}
 }
 
@@ -110,3 +113,53 @@ func TestTracerConcurrentAddEvent(t *testing.T) {
 		tids[ev.Tid] = true
 	}
 }
+
+func TestTracerWorkerRowsAndFlows(t *testing.T) {
+	tr := newTracer()
+	run := &RunInfo{Flow: "G"}
+	a := &TaskInfo{Run: run, Name: "A", Type: string(nodeStatic), Worker: 2}
+	b := &TaskInfo{Run: run, Name: "B", Type: string(nodeCondition), Worker: 1, Dependents: []string{"A"}, Choice: 1}
+	tr.AddEvent(&span{extra: attr{typ: nodeStatic, name: "A"}, begin: tr.start, cost: time.Millisecond, info: a})
+	tr.AddEvent(&span{extra: attr{typ: nodeCondition, name: "B"}, begin: tr.start.Add(2 * time.Millisecond), cost: time.Millisecond, info: b})
+
+	var buf bytes.Buffer
+	if err := tr.draw(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var events []chromeTraceEvent
+	if err := json.Unmarshal(buf.Bytes(), &events); err != nil {
+		t.Fatal(err)
+	}
+
+	byPh := map[string][]chromeTraceEvent{}
+	for _, ev := range events {
+		byPh[ev.Ph] = append(byPh[ev.Ph], ev)
+	}
+	if len(byPh["M"]) != 3 || byPh["M"][1].Tid != 1 || byPh["M"][2].Args["name"] != "worker 2" {
+		t.Errorf("unexpected metadata %+v", byPh["M"])
+	}
+	if byPh["X"][0].Tid != 2 || byPh["X"][1].Tid != 1 {
+		t.Errorf("expected events on their worker rows, got %+v", byPh["X"])
+	}
+	s, f := byPh["s"], byPh["f"]
+	if len(s) != 1 || len(f) != 1 || s[0].ID != f[0].ID || s[0].Tid != 2 || s[0].Ts != 1000 || f[0].Tid != 1 || f[0].Ts != 2000 {
+		t.Errorf("unexpected flow events %+v %+v", s, f)
+	}
+	if len(byPh["i"]) != 1 || byPh["i"][0].Args["branch"] != "1" {
+		t.Errorf("unexpected branch events %+v", byPh["i"])
+	}
+}
+
+func TestTracerLabels(t *testing.T) {
+	tr := newTracer()
+	info := &TaskInfo{Name: "A", Path: "G/A", Labels: map[string]string{"owner": "infra", "db": ""}}
+	tr.AddEvent(&span{extra: attr{typ: nodeStatic, name: "A", path: "G/A"}, begin: tr.start, info: info})
+
+	args := tr.snapshot()[0].Args
+	if args["path"] != "G/A" || args["label.owner"] != "infra" {
+		t.Errorf("unexpected args %v", args)
+	}
+	if _, ok := args["label.db"]; !ok {
+		t.Errorf("expected tag db in args %v", args)
+	}
+}
@@ -11,7 +11,7 @@ import (
 
 type cotask struct {
 	ctx *context.Context
-	f   func()
+	f   func(worker int)
 }
 
 func (ct *cotask) zero() {
@@ -25,6 +25,8 @@ type Copool struct {
 	taskQ        *Queue[*cotask]
 	corun        atomic.Int32
 	coworker     uint
+	idleIDs      []int // IDs released by exited workers, reused before new ones
+	nextID       int
 	mu           *sync.Mutex
 	taskObjPool  *ObjectPool[*cotask]
 }
@@ -52,9 +54,20 @@ func (cp *Copool) Go(f func()) {
 
 // CtxGo executes f and accepts the context.
 func (cp *Copool) CtxGo(ctx *context.Context, f func()) {
+	cp.ctxGo(ctx, func(int) { f() })
+}
+
+// GoWorker executes f, passing the ID of the worker goroutine running it.
+// IDs start at 1 and are reused, so at most cap IDs are in use at any time.
+func (cp *Copool) GoWorker(f func(worker int)) {
+	ctx := context.Background()
+	cp.ctxGo(&ctx, f)
+}
+
+func (cp *Copool) ctxGo(ctx *context.Context, f func(worker int)) {
 	cp.corun.Add(1)
 	task := cp.taskObjPool.Get()
-	task.f = func() {
+	task.f = func(worker int) {
 		defer func() {
 			if r := recover(); r != nil {
 				if cp.panicHandler != nil {
@@ -67,7 +80,7 @@ func (cp *Copool) CtxGo(ctx *context.Context, f func()) {
 			}
 		}()
 		defer cp.corun.Add(-1)
-		f()
+		f(worker)
 	}
 
 	task.ctx = ctx
@@ -76,6 +89,7 @@ func (cp *Copool) CtxGo(ctx *context.Context, f func()) {
 
 	if cp.coworker == 0 || cp.taskQ.Len() != 0 && cp.coworker < cp.cap {
 		cp.coworker++
+		id := cp.workerID()
 		cp.mu.Unlock()
 
 		go func() {
@@ -83,13 +97,14 @@ func (cp *Copool) CtxGo(ctx *context.Context, f func()) {
 				cp.mu.Lock()
 				if cp.taskQ.Len() == 0 {
 					cp.coworker--
+					cp.idleIDs = append(cp.idleIDs, id)
 					cp.mu.Unlock()
 					return
 				}
 
 				task := cp.taskQ.Pop()
 				cp.mu.Unlock()
-				task.f()
+				task.f(id)
 				task.zero()
 				cp.taskObjPool.Put(task)
 			}
@@ -100,6 +115,22 @@ func (cp *Copool) CtxGo(ctx *context.Context, f func()) {
 	}
 }
 
+// workerID returns an ID for a new worker, cp.mu must be held.
+func (cp *Copool) workerID() int {
+	if n := len(cp.idleIDs); n > 0 {
+		id := cp.idleIDs[n-1]
+		cp.idleIDs = cp.idleIDs[:n-1]
+		return id
+	}
+	cp.nextID++
+	return cp.nextID
+}
+
+// Running returns the number of tasks submitted and not finished yet.
+func (cp *Copool) Running() int {
+	return int(cp.corun.Load())
+}
+
 // SetPanicHandler sets the panic handler.
 func (cp *Copool) SetPanicHandler(f func(*context.Context, interface{})) *Copool {
 	cp.panicHandler = f
@@ -114,3 +114,26 @@ func BenchmarkGo(b *testing.B) {
 		wg.Wait()
 	}
 }
+
+func TestPoolWorkerIDs(t *testing.T) {
+	p := NewCopool(4)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	ids := map[int]bool{}
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		p.GoWorker(func(worker int) {
+			defer wg.Done()
+			mu.Lock()
+			ids[worker] = true
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+
+	for id := range ids {
+		if id < 1 || id > 4 {
+			t.Errorf("worker id %d out of range [1, 4]", id)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -44,7 +45,7 @@ func TestInvalidProfileType(t *testing.T) {
 		}
 	}()
 
-	p := NewPprofUtils(ProfileType(99), "invalid.prof")
+	p := NewPprofUtils(ProfileType(99), filepath.Join(t.TempDir(), "invalid.prof"))
 	p.StartProfile()
 	defer p.StopProfile()
 }
@@ -0,0 +1,191 @@
+package gotaskflow
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GraphError lists the problems found by TaskFlow.Validate.
+type GraphError struct {
+	Flow     string
+	Problems []string // one per problem, naming the tasks involved by their path, e.g. "sub/task"
+}
+
+func (e *GraphError) Error() string {
+	return fmt.Sprintf("invalid graph %q: %s", e.Flow, strings.Join(e.Problems, "; "))
+}
+
+// Validate checks the structure of the taskflow and of its instantiated subflows, reporting
+// cycles without a condition task, flows where no task can start, condition tasks without successors,
+// duplicate task names within a flow or subflow and dependencies between tasks of different flows or subflows.
+func (tf *TaskFlow) Validate() error {
+	c := newGraphChecker()
+	c.index(tf.graph, "")
+	c.check(tf.graph)
+	return c.err(tf.graph.name)
+}
+
+// validate checks g alone, recursing into its instantiated subflows.
+func (g *eGraph) validate() error {
+	c := newGraphChecker()
+	c.index(g, "")
+	c.check(g)
+	return c.err(g.name)
+}
+
+type graphChecker struct {
+	problems []string
+	paths    map[*innerNode]string
+	edges    map[[2]*innerNode]bool
+}
+
+func newGraphChecker() *graphChecker {
+	return &graphChecker{
+		paths: make(map[*innerNode]string),
+		edges: make(map[[2]*innerNode]bool),
+	}
+}
+
+func (c *graphChecker) err(flow string) error {
+	if len(c.problems) == 0 {
+		return nil
+	}
+	return &GraphError{Flow: flow, Problems: c.problems}
+}
+
+func (c *graphChecker) report(format string, args ...any) {
+	c.problems = append(c.problems, fmt.Sprintf(format, args...))
+}
+
+// path returns the name of n prefixed by the names of its enclosing subflows.
+func (c *graphChecker) path(n *innerNode) string {
+	if p, ok := c.paths[n]; ok {
+		return p
+	}
+	return n.name
+}
+
+// index records the path of every task of g and of its instantiated subflows.
+func (c *graphChecker) index(g *eGraph, prefix string) {
+	for _, n := range g.nodes {
+		c.paths[n] = prefix + n.name
+		if sf, ok := n.ptr.(*Subflow); ok && sf.g != nil && sf.g.instantiated {
+			c.index(sf.g, prefix+n.name+"/")
+		}
+	}
+}
+
+func (c *graphChecker) check(g *eGraph) {
+	names := make(map[string]string, len(g.nodes)) // first path of each task name
+	for _, n := range g.nodes {
+		if first, ok := names[n.name]; ok {
+			c.report("duplicate task name %q: %q and %q", n.name, first, c.path(n))
+		} else {
+			names[n.name] = c.path(n)
+		}
+
+		if n.Typ == nodeCondition && len(n.successors) == 0 {
+			c.report("condition task %q has no successors", c.path(n))
+		}
+
+		for _, succ := range n.successors {
+			c.crossEdge(g, n, succ)
+		}
+		for _, dep := range n.dependents {
+			c.crossEdge(g, dep, n)
+		}
+	}
+
+	if len(g.nodes) > 0 {
+		entry := false
+		for _, n := range g.nodes {
+			entry = entry || len(n.dependents) == 0
+		}
+		if !entry {
+			c.report("no task of %q can start, every task has a predecessor", g.name)
+		}
+	}
+	c.cycles(g)
+
+	for _, n := range g.nodes {
+		if sf, ok := n.ptr.(*Subflow); ok && sf.g != nil && sf.g.instantiated {
+			c.check(sf.g)
+		}
+	}
+}
+
+func (c *graphChecker) crossEdge(g *eGraph, from, to *innerNode) {
+	if from.g == g && to.g == g || c.edges[[2]*innerNode{from, to}] {
+		return
+	}
+	c.edges[[2]*innerNode{from, to}] = true
+	c.report("task %q precedes %q of another flow or subflow", c.path(from), c.path(to))
+}
+
+// cycles reports the strongly connected components of g joined by edges from non-condition tasks:
+// their tasks wait for each other and never become ready.
+func (c *graphChecker) cycles(g *eGraph) {
+	var (
+		index   = make(map[*innerNode]int)
+		low     = make(map[*innerNode]int)
+		onStack = make(map[*innerNode]bool)
+		stack   []*innerNode
+		visit   func(n *innerNode)
+	)
+	visit = func(n *innerNode) {
+		index[n] = len(index)
+		low[n] = index[n]
+		stack = append(stack, n)
+		onStack[n] = true
+
+		if n.Typ != nodeCondition {
+			for _, succ := range n.successors {
+				if succ.g != g {
+					continue
+				}
+				if _, seen := index[succ]; !seen {
+					visit(succ)
+					low[n] = min(low[n], low[succ])
+				} else if onStack[succ] {
+					low[n] = min(low[n], index[succ])
+				}
+			}
+		}
+
+		if low[n] != index[n] {
+			return
+		}
+		var scc []*innerNode
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			scc = append(scc, top)
+			if top == n {
+				break
+			}
+		}
+		if len(scc) > 1 || n.Typ != nodeCondition && selfLoop(n) {
+			names := make([]string, 0, len(scc))
+			for i := len(scc) - 1; i >= 0; i-- {
+				names = append(names, fmt.Sprintf("%q", c.path(scc[i])))
+			}
+			c.report("cycle without a condition task: %s", strings.Join(names, ", "))
+		}
+	}
+
+	for _, n := range g.nodes {
+		if _, seen := index[n]; !seen {
+			visit(n)
+		}
+	}
+}
+
+func selfLoop(n *innerNode) bool {
+	for _, succ := range n.successors {
+		if succ == n {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,192 @@
+package gotaskflow_test
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	gotaskflow "github.com/noneback/go-taskflow"
+)
+
+func problemsOf(t *testing.T, err error) []string {
+	t.Helper()
+	var gerr *gotaskflow.GraphError
+	if !errors.As(err, &gerr) {
+		t.Fatalf("expected *GraphError, got %v", err)
+	}
+	return gerr.Problems
+}
+
+func expectProblems(t *testing.T, problems []string, want ...string) {
+	t.Helper()
+	if len(problems) != len(want) {
+		t.Fatalf("expected %d problems, got %d: %q", len(want), len(problems), problems)
+	}
+	for i := range want {
+		if problems[i] != want[i] {
+			t.Errorf("problem %d: expected %q, got %q", i, want[i], problems[i])
+		}
+	}
+}
+
+func TestValidateValidFlow(t *testing.T) {
+	tf := gotaskflow.NewTaskFlow("G")
+	init := tf.NewTask("init", func() {})
+	body := tf.NewTask("body", func() {})
+	cond := tf.NewCondition("cond", func() uint { return 1 })
+	done := tf.NewTask("done", func() {})
+	init.Precede(body)
+	body.Precede(cond)
+	cond.Precede(body, done)
+
+	if err := tf.Validate(); err != nil {
+		t.Errorf("expected valid flow, got %v", err)
+	}
+}
+
+func TestValidateCycle(t *testing.T) {
+	tf := gotaskflow.NewTaskFlow("G")
+	A, B, C, D :=
+		tf.NewTask("A", func() {}),
+		tf.NewTask("B", func() {}),
+		tf.NewTask("C", func() {}),
+		tf.NewTask("D", func() {})
+	A.Precede(B)
+	B.Precede(C)
+	C.Precede(B)
+	D.Precede(D)
+
+	expectProblems(t, problemsOf(t, tf.Validate()),
+		`cycle without a condition task: "B", "C"`,
+		`cycle without a condition task: "D"`,
+	)
+}
+
+func TestValidateConditionAndNames(t *testing.T) {
+	tf := gotaskflow.NewTaskFlow("G")
+	A := tf.NewTask("A", func() {})
+	cond := tf.NewCondition("cond", func() uint { return 0 })
+	tf.NewTask("A", func() {})
+	A.Precede(cond)
+
+	expectProblems(t, problemsOf(t, tf.Validate()),
+		`condition task "cond" has no successors`,
+		`duplicate task name "A": "A" and "A"`,
+	)
+}
+
+func TestValidateNoEntry(t *testing.T) {
+	tf := gotaskflow.NewTaskFlow("G")
+	A := tf.NewTask("A", func() {})
+	cond := tf.NewCondition("cond", func() uint { return 0 })
+	A.Precede(cond)
+	cond.Precede(A)
+
+	expectProblems(t, problemsOf(t, tf.Validate()),
+		`no task of "G" can start, every task has a predecessor`,
+	)
+}
+
+func TestValidateCrossFlowEdge(t *testing.T) {
+	tf := gotaskflow.NewTaskFlow("G")
+	other := gotaskflow.NewTaskFlow("other")
+	A := tf.NewTask("A", func() {})
+	B := other.NewTask("B", func() {})
+	A.Precede(B)
+
+	expectProblems(t, problemsOf(t, tf.Validate()),
+		`task "A" precedes "B" of another flow or subflow`,
+	)
+}
+
+func TestValidateInstantiatedSubflow(t *testing.T) {
+	tf := gotaskflow.NewTaskFlow("G")
+	A := tf.NewTask("A", func() {})
+	tf.NewSubflow("sub", func(sf *gotaskflow.Subflow) {
+		S1 := sf.NewTask("S1", func() {})
+		S2 := sf.NewTask("S2", func() {})
+		S1.Precede(S2, A)
+	})
+	gotaskflow.NewExecutor(4).Run(tf).Wait()
+
+	expectProblems(t, problemsOf(t, tf.Validate()),
+		`task "sub/S1" precedes "A" of another flow or subflow`,
+	)
+}
+
+func TestWithValidation(t *testing.T) {
+	var ran atomic.Int32
+	tf := gotaskflow.NewTaskFlow("G")
+	A := tf.NewTask("A", func() { ran.Add(1) })
+	B := tf.NewTask("B", func() { ran.Add(1) })
+	tf.NewTask("C", func() { ran.Add(1) })
+	B.Precede(A)
+	A.Precede(B)
+
+	for _, newExecutor := range []func(opts ...gotaskflow.Option) gotaskflow.Executor{
+		func(opts ...gotaskflow.Option) gotaskflow.Executor { return gotaskflow.NewExecutor(4, opts...) },
+		func(opts ...gotaskflow.Option) gotaskflow.Executor { return gotaskflow.NewSimulator(1, opts...) },
+	} {
+		obs := &runObserver{}
+		newExecutor(gotaskflow.WithValidation(), gotaskflow.WithObserver(obs)).Run(tf).Wait()
+		if ran.Load() != 0 {
+			t.Errorf("invalid flow should not run, %d tasks ran", ran.Load())
+		}
+		var gerr *gotaskflow.GraphError
+		if len(obs.runs) != 1 || !obs.runs[0].Canceled || !errors.As(obs.runs[0].Err, &gerr) {
+			t.Errorf("expected a canceled run with the validation error, got %+v", obs.runs)
+		}
+	}
+}
+
+func TestWithValidationCoRun(t *testing.T) {
+	var err error
+	tf := gotaskflow.NewTaskFlow("G")
+	tf.NewRuntimeTask("rt", func(rt *gotaskflow.Runtime) {
+		nested := gotaskflow.NewTaskFlow("nested")
+		nested.NewCondition("dangling", func() uint { return 0 })
+		err = rt.CoRun(nested)
+	})
+
+	gotaskflow.NewExecutor(2, gotaskflow.WithValidation()).Run(tf).Wait()
+	var gerr *gotaskflow.GraphError
+	if !errors.As(err, &gerr) {
+		t.Errorf("expected CoRun to return the validation error, got %v", err)
+	}
+}
+
+func TestWithValidationSubflow(t *testing.T) {
+	var after atomic.Int32
+	tf := gotaskflow.NewTaskFlow("G")
+	sub := tf.NewSubflow("sub", func(sf *gotaskflow.Subflow) {
+		sf.NewCondition("dangling", func() uint { return 0 })
+	})
+	next := tf.NewTask("next", func() { after.Add(1) })
+	sub.Precede(next)
+
+	if err := tf.Validate(); err != nil {
+		t.Fatalf("subflow is not instantiated yet, expected valid flow, got %v", err)
+	}
+	gotaskflow.NewExecutor(4, gotaskflow.WithValidation()).Run(tf).Wait()
+	if after.Load() != 0 {
+		t.Error("invalid subflow should cancel the graph")
+	}
+	if err := tf.Validate(); err == nil || !strings.Contains(err.Error(), `condition task "sub/dangling" has no successors`) {
+		t.Errorf("expected instantiated subflow to be validated, got %v", err)
+	}
+}
+
+func TestValidateNamesPerLevel(t *testing.T) {
+	tf := gotaskflow.NewTaskFlow("G")
+	fetch := tf.NewTask("fetch", func() {})
+	sub := tf.NewSubflow("sub", func(sf *gotaskflow.Subflow) {
+		sf.NewTask("fetch", func() {})
+	})
+	fetch.Precede(sub)
+	gotaskflow.NewExecutor(2).Run(tf).Wait()
+
+	if err := tf.Validate(); err != nil {
+		t.Errorf("expected tasks of different subflows to share a name, got %v", err)
+	}
+}
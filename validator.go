@@ -2,12 +2,16 @@ package gotaskflow
 
 import (
 	"fmt"
+	"io"
+	"sort"
 	"strings"
 )
 
-// validate checks a traceRecord against the expected TaskFlow DAG.
-// A nil rec (e.g. no tracer configured) is treated as "no trace data" and always returns valid.
-// Internal-only: used in tests to verify execution correctness.
+// validate checks the task executions in rec against the graph of tf, as Verify does for user flows.
+// It reports tasks that did not run unless a condition skipped them, tasks that are not in the graph,
+// tasks whose recorded predecessors differ from those in the graph, and executions started before
+// their predecessors finished. The result is valid if none were found, a nil rec (e.g. no tracer
+// configured) always is.
 func validate(rec traceRecord, tf *TaskFlow) *validationResult {
 	if rec == nil {
 		return &validationResult{valid: true}
@@ -22,6 +26,8 @@ type validationResult struct {
 	unexpectedTasks  []string          // Tasks executed but not defined
 	dependencyErrors []dependencyError // Dependency mismatches
 	skippedBranches  []string          // Condition branches that were skipped (not errors)
+	orderErrors      []string          // Executions started before their predecessors finished
+	runs             map[string]int    // Executions per task, more than one in cyclic flows
 }
 
 // dependencyError represents a mismatch in task dependencies.
@@ -50,6 +56,9 @@ func (r *validationResult) String() string {
 	for _, e := range r.dependencyErrors {
 		sb.WriteString(fmt.Sprintf("  %s\n", e.String()))
 	}
+	for _, e := range r.orderErrors {
+		sb.WriteString(fmt.Sprintf("  %s\n", e))
+	}
 	if len(r.skippedBranches) > 0 {
 		sb.WriteString(fmt.Sprintf("  skipped branches (OK): %v\n", r.skippedBranches))
 	}
@@ -67,16 +76,20 @@ func newValidator(rec traceRecord) *validator {
 
 // run compares executed trace events against the expected TaskFlow DAG.
 func (v *validator) run(tf *TaskFlow) *validationResult {
-	result := &validationResult{valid: true}
+	result := &validationResult{valid: true, runs: make(map[string]int)}
 
-	// --- Step 1: collect expected nodes via eGraph.walk ---
+	// --- Step 1: collect expected nodes by path below the flow ---
 	expected := make(map[string]*innerNode)
-	tf.graph.walk(func(n *innerNode) { expected[n.name] = n })
+	indexNodes(tf.graph, "", expected)
 
-	// --- Step 2: build executed map from the immutable record ---
-	executed := make(map[string]chromeTraceEvent, len(v.rec))
-	for _, ev := range v.rec {
-		executed[ev.Name] = ev
+	// --- Step 2: build executed map from the immutable record, keeping the first execution per path ---
+	execs := newExecutions(v.rec, tf)
+	executed := make(map[string]*execution, len(execs))
+	for _, e := range execs {
+		if _, ok := executed[e.path]; !ok {
+			executed[e.path] = e
+		}
+		result.runs[e.path]++
 	}
 
 	// --- Step 3: missing / skipped check ---
@@ -84,76 +97,181 @@ func (v *validator) run(tf *TaskFlow) *validationResult {
 	//   (a) it is a direct successor of a condition node that chose a different branch, OR
 	//   (b) any of its non-condition predecessors is also skipped (transitive skip).
 	skipped := make(map[string]bool)
-	for name, node := range expected {
-		if _, ran := executed[name]; !ran && node.hasCondPredecessor() {
-			skipped[name] = true
+	for path, node := range expected {
+		if _, ran := executed[path]; !ran && node.hasCondPredecessor() {
+			skipped[path] = true
 		}
 	}
 	for changed := true; changed; {
 		changed = false
-		for name, node := range expected {
-			if _, ran := executed[name]; ran || skipped[name] {
+		for path, node := range expected {
+			if _, ran := executed[path]; ran || skipped[path] {
 				continue
 			}
 			for _, dep := range node.dependents {
-				if dep.Typ != nodeCondition && skipped[dep.name] {
-					skipped[name] = true
+				if dep.Typ != nodeCondition && skipped[dep.localPath()] {
+					skipped[path] = true
 					changed = true
 					break
 				}
 			}
 		}
 	}
-	for name := range expected {
-		if _, ran := executed[name]; !ran {
-			if skipped[name] {
-				result.skippedBranches = append(result.skippedBranches, name)
+	for path := range expected {
+		if _, ran := executed[path]; !ran {
+			if skipped[path] {
+				result.skippedBranches = append(result.skippedBranches, path)
 			} else {
-				result.missingTasks = append(result.missingTasks, name)
+				result.missingTasks = append(result.missingTasks, path)
 				result.valid = false
 			}
 		}
 	}
 
 	// --- Step 4: unexpected check ---
-	for name := range executed {
-		if _, defined := expected[name]; !defined {
-			result.unexpectedTasks = append(result.unexpectedTasks, name)
+	for path := range executed {
+		if _, defined := expected[path]; !defined {
+			result.unexpectedTasks = append(result.unexpectedTasks, path)
 			result.valid = false
 		}
 	}
 
-	// --- Step 5: dependency check ---
-	for name, ev := range executed {
-		node, ok := expected[name]
+	// --- Step 5: dependency check, predecessors are named relative to the graph of the task ---
+	for path, e := range executed {
+		node, ok := expected[path]
 		if !ok {
 			continue
 		}
+		dir := strings.TrimSuffix(path, e.ev.Name)
 		var expDeps []string
 		for _, dep := range node.dependents {
-			if _, ran := executed[dep.name]; ran {
+			if _, ran := executed[dir+dep.name]; ran {
 				expDeps = append(expDeps, dep.name)
 			}
 		}
 		var actDeps []string
-		if raw := ev.Args["dependents"]; raw != "" {
+		if raw := e.ev.Args["dependents"]; raw != "" {
 			for _, d := range strings.Split(raw, ",") {
-				if _, ran := executed[d]; ran {
+				if _, ran := executed[dir+d]; ran {
 					actDeps = append(actDeps, d)
 				}
 			}
 		}
 		if !stringSliceEqual(expDeps, actDeps) {
 			result.dependencyErrors = append(result.dependencyErrors, dependencyError{
-				task: name, expected: expDeps, actual: actDeps,
+				task: path, expected: expDeps, actual: actDeps,
 			})
 			result.valid = false
 		}
 	}
 
+	// --- Step 6: order check, for every execution ---
+	result.orderErrors = checkOrder(execs)
+	if len(result.orderErrors) > 0 {
+		result.valid = false
+	}
+
 	return result
 }
 
+// checkOrder reports the executions started before the predecessor executions that made them ready:
+// a task waits for its non-condition predecessors, unless a condition predecessor started it.
+func checkOrder(execs []*execution) []string {
+	byScope := make(map[execScope][]*execution)
+	for _, e := range execs {
+		byScope[execScope{e.parent, e.ev.Name}] = append(byScope[execScope{e.parent, e.ev.Name}], e)
+	}
+	// conditions schedule their successor before their own execution ends
+	before := func(dep *innerNode, e *execution) (ran, ok bool) {
+		for _, c := range byScope[execScope{e.parent, dep.name}] {
+			ran = true
+			if dep.Typ == nodeCondition && c.start <= e.start || c.end <= e.start {
+				return true, true
+			}
+		}
+		return ran, false
+	}
+
+	var errs []string
+	for _, e := range execs {
+		if e.node == nil {
+			continue
+		}
+		var late []string
+		chosen := false
+		for _, dep := range e.node.dependents {
+			ran, ok := before(dep, e)
+			if dep.Typ == nodeCondition {
+				chosen = chosen || ok
+			} else if ran && !ok {
+				late = append(late, dep.name)
+			}
+		}
+		if len(late) > 0 && !chosen {
+			errs = append(errs, fmt.Sprintf("task %q started before %q finished", e.path, strings.Join(late, `", "`)))
+		}
+	}
+	return errs
+}
+
+// ExecutionReport is the result of checking a traced run against its TaskFlow, see Verify.
+// Tasks are named by their path below the flow, e.g. "sub/task", so that a run can be checked
+// against another TaskFlow declaring the same tasks.
+type ExecutionReport struct {
+	Missing    []string       // tasks that did not run although no condition skipped them
+	Unexpected []string       // tasks that ran but are not part of the TaskFlow
+	Skipped    []string       // tasks that did not run since a condition took another branch
+	Violations []string       // executions that disagree with the dependencies of the TaskFlow
+	Runs       map[string]int // number of executions of each task
+}
+
+// OK tells whether every task ran after its predecessors and no task is missing or unexpected.
+func (r *ExecutionReport) OK() bool {
+	return len(r.Missing) == 0 && len(r.Unexpected) == 0 && len(r.Violations) == 0
+}
+
+func (r *ExecutionReport) String() string {
+	if r.OK() {
+		return "execution matches the graph"
+	}
+	var sb strings.Builder
+	sb.WriteString("execution does not match the graph:\n")
+	if len(r.Missing) > 0 {
+		sb.WriteString(fmt.Sprintf("  missing tasks: %q\n", r.Missing))
+	}
+	if len(r.Unexpected) > 0 {
+		sb.WriteString(fmt.Sprintf("  unexpected tasks: %q\n", r.Unexpected))
+	}
+	for _, v := range r.Violations {
+		sb.WriteString("  " + v + "\n")
+	}
+	return sb.String()
+}
+
+// Verify checks the run of tf recorded in trace, as written by Executor.Trace for an executor that ran
+// tf once, against the graph of tf. Cyclic flows are supported, every execution of a task is checked.
+func Verify(trace io.Reader, tf *TaskFlow) (*ExecutionReport, error) {
+	events, err := readTrace(trace)
+	if err != nil {
+		return nil, err
+	}
+	result := validate(tasksOf(events), tf)
+	r := &ExecutionReport{
+		Missing:    result.missingTasks,
+		Unexpected: result.unexpectedTasks,
+		Skipped:    result.skippedBranches,
+		Runs:       result.runs,
+	}
+	for _, e := range result.dependencyErrors {
+		r.Violations = append(r.Violations, e.String())
+	}
+	r.Violations = append(r.Violations, result.orderErrors...)
+	sort.Strings(r.Missing)
+	sort.Strings(r.Unexpected)
+	sort.Strings(r.Skipped)
+	return r, nil
+}
+
 // stringSliceEqual checks if two string slices contain the same elements (order-independent).
 func stringSliceEqual(a, b []string) bool {
 	if len(a) != len(b) {
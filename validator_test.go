@@ -1,8 +1,10 @@
 package gotaskflow
 
 import (
+	"bytes"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestValidatorSimpleSerial(t *testing.T) {
@@ -302,6 +304,112 @@ func TestValidatorResultString(t *testing.T) {
 	}
 }
 
+// TestValidatorOrder verifies that executions started before their predecessors finished are reported.
+func TestValidatorOrder(t *testing.T) {
+	tf := NewTaskFlow("order")
+	A := tf.NewTask("A", func() {})
+	B := tf.NewTask("B", func() {})
+	A.Precede(B)
+
+	rec := traceRecord{
+		{Name: "A", Cat: "static", Ph: "X", Ts: 0, Dur: 10},
+		{Name: "B", Cat: "static", Ph: "X", Ts: 5, Dur: 10, Args: map[string]string{"dependents": "A"}},
+	}
+	result := validate(rec, tf)
+	if result.valid || len(result.orderErrors) != 1 || !containsSubstr(result.orderErrors[0], `"B" started before "A"`) {
+		t.Errorf("expected B reported as started before A, got: %s", result.String())
+	}
+}
+
+// TestValidatorCycle verifies that every execution of a task in a cyclic flow is counted and checked.
+func TestValidatorCycle(t *testing.T) {
+	executor := NewExecutor(4, WithTracer())
+	tf := NewTaskFlow("cycle")
+
+	i := 0
+	init := tf.NewTask("init", func() {})
+	loop := tf.NewCondition("loop", func() uint {
+		if i < 3 {
+			return 0
+		}
+		return 1
+	})
+	body := tf.NewCondition("body", func() uint { i++; return 0 })
+	done := tf.NewTask("done", func() {})
+	init.Precede(loop)
+	loop.Precede(body, done)
+	body.Precede(loop)
+
+	executor.Run(tf).Wait()
+
+	result := validate(mustSnapshot(executor), tf)
+	if !result.valid {
+		t.Errorf("expected valid, got: %s", result.String())
+	}
+	if result.runs["body"] != 3 || result.runs["loop"] != 4 {
+		t.Errorf("expected body to run 3 times and loop 4 times, got: %v", result.runs)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	executor := NewExecutor(4, WithTracer())
+	tf := NewTaskFlow("verify")
+	cond := tf.NewCondition("cond", func() uint { return 0 })
+	A := tf.NewTask("A", func() {})
+	B := tf.NewTask("B", func() {})
+	cond.Precede(A, B)
+	executor.Run(tf).Wait()
+
+	var buf bytes.Buffer
+	if err := executor.Trace(&buf); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Verify(&buf, tf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.OK() || len(r.Skipped) != 1 || r.Skipped[0] != "B" || r.Runs["A"] != 1 {
+		t.Errorf("unexpected report %+v", r)
+	}
+
+	if _, err := Verify(strings.NewReader(""), tf); err == nil {
+		t.Error("expected an error for an empty trace")
+	}
+}
+
+func TestValidatorSameNames(t *testing.T) {
+	executor := NewExecutor(4, WithTracer(), WithProfiler())
+	tf := NewTaskFlow("G")
+	newSub := func(name string) *Task {
+		return tf.NewSubflow(name, func(sf *Subflow) {
+			fetch := sf.NewTask("fetch", func() { time.Sleep(time.Millisecond) })
+			fetch.Precede(sf.NewTask("parse", func() {}))
+		})
+	}
+	a, b := newSub("a"), newSub("b")
+	a.Precede(b)
+	executor.Run(tf).Wait()
+
+	rec := mustSnapshot(executor)
+	result := validate(rec, tf)
+	if !result.valid || result.runs["a/fetch"] != 1 || result.runs["b/fetch"] != 1 {
+		t.Errorf("expected both fetch tasks to be checked: %s, runs %v", result, result.runs)
+	}
+	for _, ev := range rec {
+		if ev.Name == "fetch" && ev.Args["path"] != "G/"+ev.Args["parent"]+"/fetch" {
+			t.Errorf("unexpected path %q of fetch in %q", ev.Args["path"], ev.Args["parent"])
+		}
+	}
+
+	var profile bytes.Buffer
+	if err := executor.Profile(&profile); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(profile.String(), "static,fetch,"); n != 2 {
+		t.Errorf("expected fetch tasks profiled apart, got %d in:\n%s", n, profile.String())
+	}
+}
+
 // ---- helpers ----
 
 // mustSnapshot extracts a traceRecord from an executor.
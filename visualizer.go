@@ -1,12 +1,77 @@
 package gotaskflow
 
 import (
+	"fmt"
 	"io"
 )
 
 var dot = dotVizer{}
 
+// Visualizer renders the structure of a TaskFlow, see TaskFlow.DumpWith.
 type Visualizer interface {
-	// Visualize generate raw dag text in dot format and write to writer
+	// Visualize generate raw dag text and write to writer
 	Visualize(tf *TaskFlow, writer io.Writer) error
 }
+
+// NewDOTVisualizer returns a Visualizer writing Graphviz DOT, as TaskFlow.Dump does.
+func NewDOTVisualizer() Visualizer {
+	return &dotVizer{}
+}
+
+// NewMermaidVisualizer returns a Visualizer writing a Mermaid flowchart, for embedding in Markdown.
+func NewMermaidVisualizer() Visualizer {
+	return &mermaidVizer{}
+}
+
+// NewJSONVisualizer returns a Visualizer writing nodes, edges, types, priorities and nested subflows as JSON.
+func NewJSONVisualizer() Visualizer {
+	return &jsonVizer{}
+}
+
+// NewGraphMLVisualizer returns a Visualizer writing GraphML, subflows as nested graphs.
+func NewGraphMLVisualizer() Visualizer {
+	return &graphMLVizer{}
+}
+
+// NewFilterVisualizer returns a Visualizer rendering with v only the tasks for which keep returns true and
+// the edges between them, e.g. NewFilterVisualizer(NewDOTVisualizer(), Labeled("db")). Subflows are kept
+// around the tasks they hold. v must be one of the Visualizers of this package.
+func NewFilterVisualizer(v Visualizer, keep func(*Task) bool) Visualizer {
+	f := nodeFilter(func(n *innerNode) bool { return keep(n.task) })
+	switch v := v.(type) {
+	case *dotVizer:
+		c := *v
+		c.keep = f
+		return &c
+	case *execVizer:
+		c := *v
+		c.keep = f
+		return &c
+	case *mermaidVizer:
+		return &mermaidVizer{keep: f}
+	case *jsonVizer:
+		return &jsonVizer{keep: f}
+	case *graphMLVizer:
+		return &graphMLVizer{keep: f}
+	default:
+		panic(fmt.Sprintf("cannot filter the tasks rendered by %T", v))
+	}
+}
+
+// nodeFilter selects the nodes rendered by a Visualizer, every node if nil.
+type nodeFilter func(*innerNode) bool
+
+// visible tells whether n is selected or is a subflow holding a selected task.
+func (f nodeFilter) visible(n *innerNode) bool {
+	if f == nil || f(n) {
+		return true
+	}
+	if sf, ok := n.ptr.(*Subflow); ok && sf.g != nil {
+		for _, child := range sf.g.nodes {
+			if f.visible(child) {
+				return true
+			}
+		}
+	}
+	return false
+}
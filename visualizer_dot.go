@@ -6,7 +6,14 @@ import (
 	"strings"
 )
 
-type dotVizer struct{}
+type dotVizer struct {
+	critical      map[*innerNode]bool // nodes to highlight
+	criticalEdges map[[2]*innerNode]bool
+	run           *runAnnotation // outcome of a traced run, nil for the structure only
+	keep          nodeFilter
+}
+
+const criticalColor = "#d62728"
 
 // dotGraph represents a graph in DOT format
 type dotGraph struct {
@@ -157,9 +164,13 @@ func (v *dotVizer) visualizeG(g *eGraph, parentGraph *dotGraph) error {
 	graph := parentGraph
 	graph.attributes["rankdir"] = "LR"
 
-	nodeMap := make(map[string]*dotNode)
+	// nodes are identified by their path, tasks of different subflows may share a name
+	nodeMap := make(map[*innerNode]*dotNode)
 
 	for _, node := range g.nodes {
+		if !v.keep.visible(node) {
+			continue
+		}
 		color := "black"
 		if node.priority == HIGH {
 			color = "#f5427b"
@@ -169,46 +180,51 @@ func (v *dotVizer) visualizeG(g *eGraph, parentGraph *dotGraph) error {
 
 		switch p := node.ptr.(type) {
 		case *Static:
-			dotNode := graph.CreateNode(node.name)
+			dotNode := graph.CreateNode(node.path())
+			dotNode.attributes["label"] = node.name
 			dotNode.attributes["color"] = color
-			nodeMap[node.name] = dotNode
+			v.highlight(node, dotNode.attributes)
+			nodeMap[node] = dotNode
 
 		case *Condition:
-			dotNode := graph.CreateNode(node.name)
+			dotNode := graph.CreateNode(node.path())
+			dotNode.attributes["label"] = node.name
 			dotNode.attributes["shape"] = "diamond"
 			dotNode.attributes["color"] = "green"
-			nodeMap[node.name] = dotNode
+			v.highlight(node, dotNode.attributes)
+			nodeMap[node] = dotNode
 
 		case *Subflow:
-			subgraph := graph.SubGraph(node.name)
+			subgraph := graph.SubGraph(node.path())
 			subgraph.attributes["label"] = node.name
 			subgraph.attributes["style"] = "dashed"
 			subgraph.attributes["rankdir"] = "LR"
 			subgraph.attributes["bgcolor"] = "#F5F5F5"
 			subgraph.attributes["fontcolor"] = color
+			v.highlight(node, subgraph.attributes)
 
-			subgraphDot := subgraph.CreateNode(node.name)
+			subgraphDot := subgraph.CreateNode(node.path())
 			subgraphDot.attributes["shape"] = "point"
 			subgraphDot.attributes["height"] = "0.05"
 			subgraphDot.attributes["width"] = "0.05"
 
-			nodeMap[node.name] = subgraphDot
+			nodeMap[node] = subgraphDot
 
 			err := v.visualizeG(p.g, subgraph)
 			if err != nil {
-				errorNodeName := "unvisualized_subflow_" + p.g.name
+				errorNodeName := "unvisualized_subflow_" + node.path()
 				dotNode := graph.CreateNode(errorNodeName)
 				dotNode.attributes["color"] = "#a10212"
 				dotNode.attributes["comment"] = "cannot visualize due to instantiate panic or failed"
-				nodeMap[node.name] = dotNode
+				nodeMap[node] = dotNode
 			}
 		}
 	}
 
 	for _, node := range g.nodes {
 		for idx, deps := range node.successors {
-			if from, ok := nodeMap[node.name]; ok {
-				if to, ok := nodeMap[deps.name]; ok {
+			if from, ok := nodeMap[node]; ok {
+				if to, ok := nodeMap[deps]; ok {
 					label := ""
 					style := "solid"
 					if _, ok := node.ptr.(*Condition); ok {
@@ -220,6 +236,13 @@ func (v *dotVizer) visualizeG(g *eGraph, parentGraph *dotGraph) error {
 					if style != "solid" {
 						edge.attributes["style"] = style
 					}
+					if v.run != nil && node.Typ == nodeCondition {
+						v.run.annotateEdge(node, idx, edge.attributes)
+					}
+					if v.criticalEdges[[2]*innerNode{node, deps}] {
+						edge.attributes["color"] = criticalColor
+						edge.attributes["penwidth"] = "3"
+					}
 				}
 			}
 		}
@@ -228,6 +251,17 @@ func (v *dotVizer) visualizeG(g *eGraph, parentGraph *dotGraph) error {
 	return nil
 }
 
+// highlight marks node as part of the critical path and annotates it with its run.
+func (v *dotVizer) highlight(node *innerNode, attrs map[string]string) {
+	if v.run != nil {
+		v.run.annotate(node, attrs)
+	}
+	if v.critical[node] {
+		attrs["color"] = criticalColor
+		attrs["penwidth"] = "3"
+	}
+}
+
 // Visualize generates raw dag text in dot format and writes to writer
 func (v *dotVizer) Visualize(tf *TaskFlow, writer io.Writer) error {
 	graph := newDotGraph(tf.graph.name)
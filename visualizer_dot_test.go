@@ -95,11 +95,12 @@ func TestDotVizer_Visualize(t *testing.T) {
 	expectedParts := []string{
 		`digraph "test_flow" {`,
 		`rankdir="LR";`,
-		`"A"`,
-		`"B"`,
-		`"C"`,
-		`"A" -> "B"`,
-		`"C" -> "B"`,
+		`"test_flow/A" [`,
+		`label="A"`,
+		`"test_flow/B" [`,
+		`"test_flow/C" [`,
+		`"test_flow/A" -> "test_flow/B"`,
+		`"test_flow/C" -> "test_flow/B"`,
 	}
 
 	for _, part := range expectedParts {
@@ -141,14 +142,14 @@ func TestDotVizer_VisualizeComplex(t *testing.T) {
 	expectedParts := []string{
 		`digraph "complex_flow" {`,
 		`rankdir="LR";`,
-		`"A"`,
-		`"B"`,
-		`"cond" `,
-		`subgraph "cluster_sub" {`,
-		`"sub"`,
-		`"A" -> "B"`,
-		`"B" -> "cond"`,
-		`"cond" -> "sub"`,
+		`"complex_flow/A" [`,
+		`"complex_flow/B" [`,
+		`"complex_flow/cond" [`,
+		`subgraph "cluster_complex_flow/sub" {`,
+		`"complex_flow/sub" [`,
+		`"complex_flow/A" -> "complex_flow/B"`,
+		`"complex_flow/B" -> "complex_flow/cond"`,
+		`"complex_flow/cond" -> "complex_flow/sub"`,
 	}
 
 	for _, part := range expectedParts {
@@ -0,0 +1,208 @@
+package gotaskflow
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/noneback/go-taskflow/utils"
+)
+
+// execVizer renders a TaskFlow in DOT format annotated with a traced run.
+type execVizer struct {
+	events []chromeTraceEvent
+	keep   nodeFilter
+}
+
+// NewExecutionVisualizer returns a Visualizer writing a TaskFlow in DOT format annotated with the run
+// recorded in trace, as written by Executor.Trace: nodes are colored by outcome and labeled with their
+// wall time and number of executions, condition edges taken are drawn bold.
+func NewExecutionVisualizer(trace io.Reader) (Visualizer, error) {
+	events, err := readTrace(trace)
+	if err != nil {
+		return nil, err
+	}
+	return &execVizer{events: events}, nil
+}
+
+// nodeOutcome tells how a task ended in a traced run, including the tasks that never ran.
+type nodeOutcome int
+
+const (
+	outcomeUnreached nodeOutcome = iota // did not run although no condition skipped it
+	outcomeSkipped                      // did not run since a condition took another branch
+	outcomeCached                       // did not run since its output was restored from cache
+	outcomeFinished
+	outcomeRestored
+	outcomeCanceled
+	outcomeFailed
+)
+
+var outcomeColors = map[nodeOutcome]string{
+	outcomeUnreached: "white",
+	outcomeSkipped:   "#e0e0e0",
+	outcomeCached:    "#d9c8f0",
+	outcomeFinished:  "#b7e1a1",
+	outcomeRestored:  "#a6c8f4",
+	outcomeCanceled:  "#ffd59e",
+	outcomeFailed:    "#f4a6a6",
+}
+
+// nodeRun sums up the executions of a task in a traced run.
+type nodeRun struct {
+	outcome  nodeOutcome
+	attempts int
+	cost     time.Duration
+}
+
+// runAnnotation is a traced run matched with the nodes of a TaskFlow.
+type runAnnotation struct {
+	nodes    map[*innerNode]*nodeRun
+	branches map[*innerNode]map[int]bool // branches taken by each condition
+}
+
+func newRunAnnotation(events []chromeTraceEvent, tf *TaskFlow) *runAnnotation {
+	ann := &runAnnotation{
+		nodes:    make(map[*innerNode]*nodeRun),
+		branches: make(map[*innerNode]map[int]bool),
+	}
+
+	// instant events of conditions are emitted on the row of the execution, when it ends
+	type instant struct {
+		name string
+		tid  int64
+		ts   int64
+	}
+	chosen := make(map[instant]int)
+	for _, ev := range events {
+		if ev.Ph == "i" && ev.Cat == "branch" {
+			if b, err := strconv.Atoi(ev.Args["branch"]); err == nil {
+				chosen[instant{ev.Name, ev.Tid, ev.Ts}] = b
+			}
+		}
+	}
+
+	for _, e := range newExecutions(tasksOf(events), tf) {
+		if e.node == nil {
+			continue
+		}
+		r, ok := ann.nodes[e.node]
+		if !ok {
+			r = &nodeRun{}
+			ann.nodes[e.node] = r
+		}
+		r.attempts++
+		r.cost += time.Duration(e.ev.Dur) * time.Microsecond
+
+		var outcome nodeOutcome
+		switch e.ev.Args["outcome"] {
+		case "":
+			outcome = outcomeFinished
+		case TaskCached.String():
+			outcome = outcomeCached
+		case TaskRestored.String():
+			outcome = outcomeRestored
+		case TaskCanceled.String():
+			outcome = outcomeCanceled
+		default:
+			outcome = outcomeFailed
+		}
+		if outcome > r.outcome {
+			r.outcome = outcome
+		}
+
+		if b, ok := chosen[instant{e.ev.Name, e.ev.Tid, e.ev.Ts + e.ev.Dur}]; ok {
+			if ann.branches[e.node] == nil {
+				ann.branches[e.node] = make(map[int]bool)
+			}
+			ann.branches[e.node][b] = true
+		}
+	}
+
+	// a task that did not run is skipped if a condition before it took another branch,
+	// directly or through tasks skipped themselves
+	for changed := true; changed; {
+		changed = false
+		tf.graph.walk(func(n *innerNode) {
+			if _, ok := ann.nodes[n]; ok {
+				return
+			}
+			for _, dep := range n.dependents {
+				if dep.Typ == nodeCondition && ann.notTaken(dep, n) || dep.Typ != nodeCondition && ann.skipped(dep) {
+					ann.nodes[n] = &nodeRun{outcome: outcomeSkipped}
+					changed = true
+					return
+				}
+			}
+		})
+	}
+	return ann
+}
+
+// notTaken tells whether cond ran without ever choosing succ.
+func (ann *runAnnotation) notTaken(cond, succ *innerNode) bool {
+	if !ann.ran(cond) {
+		return false
+	}
+	for idx, n := range cond.successors {
+		if n == succ && ann.branches[cond][idx] {
+			return false
+		}
+	}
+	return true
+}
+
+func (ann *runAnnotation) ran(n *innerNode) bool {
+	r, ok := ann.nodes[n]
+	return ok && r.attempts > 0
+}
+
+func (ann *runAnnotation) skipped(n *innerNode) bool {
+	r, ok := ann.nodes[n]
+	return ok && r.outcome == outcomeSkipped
+}
+
+// annotate styles the DOT attributes of node after its run.
+func (ann *runAnnotation) annotate(node *innerNode, attrs map[string]string) {
+	r, ok := ann.nodes[node]
+	if !ok {
+		r = &nodeRun{outcome: outcomeUnreached}
+	}
+
+	label := node.name
+	if r.attempts > 0 {
+		label += `\n` + utils.NormalizeDuration(r.cost)
+		if r.attempts > 1 {
+			label += fmt.Sprintf(" ×%d", r.attempts)
+		}
+	}
+	attrs["label"] = label
+	if node.Typ == nodeSubflow {
+		attrs["bgcolor"] = outcomeColors[r.outcome]
+	} else {
+		attrs["style"] = "filled"
+		attrs["fillcolor"] = outcomeColors[r.outcome]
+	}
+	if r.outcome == outcomeUnreached {
+		attrs["style"] = "dashed"
+	}
+}
+
+// annotateEdge styles the edge of a condition to its successor at branch.
+func (ann *runAnnotation) annotateEdge(cond *innerNode, branch int, attrs map[string]string) {
+	if !ann.ran(cond) {
+		return
+	}
+	if ann.branches[cond][branch] {
+		attrs["style"] = "bold"
+		attrs["label"] += " taken"
+	} else {
+		attrs["color"] = "gray"
+	}
+}
+
+// Visualize generates raw dag text in dot format, annotated with the traced run, and writes to writer
+func (v *execVizer) Visualize(tf *TaskFlow, writer io.Writer) error {
+	return (&dotVizer{run: newRunAnnotation(v.events, tf), keep: v.keep}).Visualize(tf, writer)
+}
@@ -0,0 +1,135 @@
+package gotaskflow
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// dotLine returns the line of the DOT output declaring the node or edge prefixed by prefix.
+func dotLine(t *testing.T, dot, prefix string) string {
+	t.Helper()
+	for _, line := range strings.Split(dot, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), prefix) {
+			return line
+		}
+	}
+	t.Fatalf("no line starting with %s in:\n%s", prefix, dot)
+	return ""
+}
+
+func TestExecVizer_Outcomes(t *testing.T) {
+	executor := NewExecutor(4, WithTracer())
+	tf := NewTaskFlow("G")
+	init := tf.NewTask("init", func() {})
+	cond := tf.NewCondition("cond", func() uint { return 1 })
+	skipped := tf.NewTask("skipped", func() {})
+	skippedNext := tf.NewTask("skippedNext", func() {})
+	B := tf.NewTask("B", func() {})
+	fail := tf.NewTask("fail", func() { panic("fail") })
+	after := tf.NewTask("after", func() {})
+	init.Precede(cond)
+	cond.Precede(skipped, B)
+	skipped.Precede(skippedNext)
+	B.Precede(fail)
+	fail.Precede(after)
+	executor.Run(tf).Wait()
+
+	// render from a saved trace file
+	path := filepath.Join(t.TempDir(), "trace.json")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := executor.Trace(f); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	f, err = os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	v, err := NewExecutionVisualizer(f)
+	if err != nil {
+		t.Fatalf("NewExecutionVisualizer error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tf.DumpWith(v, &buf); err != nil {
+		t.Fatal(err)
+	}
+	dot := buf.String()
+
+	for node, color := range map[string]string{
+		`"G/init" [`:        outcomeColors[outcomeFinished],
+		`"G/B" [`:           outcomeColors[outcomeFinished],
+		`"G/fail" [`:        outcomeColors[outcomeFailed],
+		`"G/skipped" [`:     outcomeColors[outcomeSkipped],
+		`"G/skippedNext" [`: outcomeColors[outcomeSkipped],
+	} {
+		if line := dotLine(t, dot, node); !strings.Contains(line, `fillcolor="`+color+`"`) {
+			t.Errorf("expected %s colored %s: %s", node, color, line)
+		}
+	}
+	if line := dotLine(t, dot, `"G/after" [`); !strings.Contains(line, `style="dashed"`) {
+		t.Errorf("expected after to be marked never reached: %s", line)
+	}
+	if line := dotLine(t, dot, `"G/init" [`); !strings.Contains(line, `label="init\n`) {
+		t.Errorf("expected init labeled with its duration: %s", line)
+	}
+	if line := dotLine(t, dot, `"G/cond" -> "G/B"`); !strings.Contains(line, `label="1 taken"`) || !strings.Contains(line, `style="bold"`) {
+		t.Errorf("expected taken branch to be marked: %s", line)
+	}
+	if line := dotLine(t, dot, `"G/cond" -> "G/skipped"`); !strings.Contains(line, `color="gray"`) {
+		t.Errorf("expected branch not taken to be gray: %s", line)
+	}
+}
+
+func TestExecVizer_Attempts(t *testing.T) {
+	executor := NewExecutor(4, WithTracer())
+	tf := NewTaskFlow("loop")
+	i := 0
+	init := tf.NewTask("init", func() { i = 0 })
+	body := tf.NewTask("body", func() { i++ })
+	cond := tf.NewCondition("again", func() uint {
+		if i < 3 {
+			return 0
+		}
+		return 1
+	})
+	done := tf.NewTask("done", func() {})
+	init.Precede(body)
+	body.Precede(cond)
+	cond.Precede(body, done)
+	executor.Run(tf).Wait()
+
+	var trace bytes.Buffer
+	executor.Trace(&trace)
+	v, err := NewExecutionVisualizer(&trace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := v.Visualize(tf, &buf); err != nil {
+		t.Fatal(err)
+	}
+	dot := buf.String()
+
+	if line := dotLine(t, dot, `"loop/body" [`); !strings.Contains(line, "×3") {
+		t.Errorf("expected body to have run 3 times: %s", line)
+	}
+	for _, edge := range []string{`"loop/again" -> "loop/body"`, `"loop/again" -> "loop/done"`} {
+		if line := dotLine(t, dot, edge); !strings.Contains(line, "taken") {
+			t.Errorf("expected both branches taken: %s", line)
+		}
+	}
+}
+
+func TestExecVizer_InvalidTrace(t *testing.T) {
+	if _, err := NewExecutionVisualizer(strings.NewReader("not json")); err == nil {
+		t.Error("expected error for invalid trace")
+	}
+}
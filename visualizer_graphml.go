@@ -0,0 +1,123 @@
+package gotaskflow
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type graphMLVizer struct {
+	keep nodeFilter
+}
+
+type graphMLDoc struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Data        []graphMLData `xml:"data"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID    string        `xml:"id,attr"`
+	Data  []graphMLData `xml:"data"`
+	Graph *graphMLGraph `xml:"graph,omitempty"` // instantiated subflow
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data,omitempty"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// newGraphMLGraph converts g; node ids are prefixed by the id of the enclosing subflow and "::",
+// as nested graphs do in GraphML.
+func newGraphMLGraph(g *eGraph, id, prefix string, keep nodeFilter) graphMLGraph {
+	gg := graphMLGraph{
+		ID:          id,
+		EdgeDefault: "directed",
+		Data:        []graphMLData{{Key: "name", Value: g.name}},
+	}
+	ids := make(map[*innerNode]string, len(g.nodes))
+	for i, node := range g.nodes {
+		ids[node] = prefix + "n" + strconv.Itoa(i)
+	}
+
+	for _, node := range g.nodes {
+		if !keep.visible(node) {
+			continue
+		}
+		gn := graphMLNode{
+			ID: ids[node],
+			Data: []graphMLData{
+				{Key: "name", Value: node.name},
+				{Key: "type", Value: string(node.Typ)},
+				{Key: "priority", Value: node.priority.String()},
+			},
+		}
+		if p, ok := node.ptr.(*Subflow); ok && p.g != nil {
+			sub := newGraphMLGraph(p.g, ids[node]+":", ids[node]+"::", keep)
+			gn.Graph = &sub
+		}
+		gg.Nodes = append(gg.Nodes, gn)
+
+		for idx, succ := range node.successors {
+			if !keep.visible(succ) {
+				continue
+			}
+			edge := graphMLEdge{Source: ids[node], Target: ids[succ]}
+			if node.Typ == nodeCondition {
+				edge.Data = []graphMLData{{Key: "branch", Value: strconv.Itoa(idx)}}
+			}
+			gg.Edges = append(gg.Edges, edge)
+		}
+	}
+	return gg
+}
+
+// Visualize generates the graph in GraphML and writes to writer
+func (v *graphMLVizer) Visualize(tf *TaskFlow, writer io.Writer) error {
+	doc := graphMLDoc{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "name", For: "all", Name: "name", Type: "string"},
+			{ID: "type", For: "node", Name: "type", Type: "string"},
+			{ID: "priority", For: "node", Name: "priority", Type: "string"},
+			{ID: "branch", For: "edge", Name: "branch", Type: "int"},
+		},
+		Graph: newGraphMLGraph(tf.graph, "G", "", v.keep),
+	}
+
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return fmt.Errorf("write graphml output -> %w", err)
+	}
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("write graphml output -> %w", err)
+	}
+	if _, err := io.WriteString(writer, "\n"); err != nil {
+		return fmt.Errorf("write graphml output -> %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package gotaskflow
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+)
+
+func TestGraphMLVizer_Visualize(t *testing.T) {
+	tf := newVisualizerTestFlow()
+
+	var buf bytes.Buffer
+	if err := tf.DumpWith(NewGraphMLVisualizer(), &buf); err != nil {
+		t.Fatalf("Visualize returned an error: %v", err)
+	}
+
+	var doc graphMLDoc
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid XML: %v\n%s", err, buf.String())
+	}
+	if len(doc.Keys) != 4 || doc.Graph.EdgeDefault != "directed" || len(doc.Graph.Nodes) != 5 {
+		t.Fatalf("unexpected document %+v", doc)
+	}
+
+	sub := doc.Graph.Nodes[2]
+	if sub.Data[0].Value != "sub" || sub.Data[1].Value != "subflow" || sub.Graph == nil {
+		t.Fatalf("expected nested graph for subflow, got %+v", sub)
+	}
+	if len(sub.Graph.Nodes) != 2 || sub.Graph.Nodes[0].ID != "n2::n0" {
+		t.Errorf("unexpected nested nodes %+v", sub.Graph.Nodes)
+	}
+	if len(sub.Graph.Edges) != 1 || sub.Graph.Edges[0].Source != "n2::n0" || sub.Graph.Edges[0].Target != "n2::n1" {
+		t.Errorf("unexpected nested edges %+v", sub.Graph.Edges)
+	}
+
+	var branches []string
+	for _, e := range doc.Graph.Edges {
+		if e.Source == "n3" {
+			if len(e.Data) != 1 || e.Data[0].Key != "branch" {
+				t.Fatalf("condition edge without branch: %+v", e)
+			}
+			branches = append(branches, e.Target+"="+e.Data[0].Value)
+		}
+	}
+	if len(branches) != 2 || branches[0] != "n4=0" || branches[1] != "n1=1" {
+		t.Errorf("unexpected condition branches %v", branches)
+	}
+}
@@ -0,0 +1,84 @@
+package gotaskflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type jsonVizer struct {
+	keep nodeFilter
+}
+
+// jsonGraph is the JSON form of a TaskFlow or an instantiated subflow.
+type jsonGraph struct {
+	Name  string     `json:"name"`
+	Nodes []jsonNode `json:"nodes"`
+	Edges []jsonEdge `json:"edges"`
+}
+
+type jsonNode struct {
+	ID       string     `json:"id"` // position in its graph, prefixed by the id of the enclosing subflow
+	Name     string     `json:"name"`
+	Type     string     `json:"type"`
+	Priority string     `json:"priority"`
+	Subflow  *jsonGraph `json:"subflow,omitempty"`
+}
+
+type jsonEdge struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Branch *int   `json:"branch,omitempty"` // index of the successor returned by a condition
+}
+
+func newJSONGraph(g *eGraph, prefix string, keep nodeFilter) *jsonGraph {
+	jg := &jsonGraph{
+		Name:  g.name,
+		Nodes: make([]jsonNode, 0, len(g.nodes)),
+		Edges: make([]jsonEdge, 0),
+	}
+	ids := make(map[*innerNode]string, len(g.nodes))
+	for i, node := range g.nodes {
+		ids[node] = prefix + strconv.Itoa(i)
+	}
+
+	for _, node := range g.nodes {
+		if !keep.visible(node) {
+			continue
+		}
+		jn := jsonNode{
+			ID:       ids[node],
+			Name:     node.name,
+			Type:     string(node.Typ),
+			Priority: node.priority.String(),
+		}
+		if p, ok := node.ptr.(*Subflow); ok && p.g != nil {
+			jn.Subflow = newJSONGraph(p.g, jn.ID+"/", keep)
+		}
+		jg.Nodes = append(jg.Nodes, jn)
+
+		for idx, succ := range node.successors {
+			if !keep.visible(succ) {
+				continue
+			}
+			edge := jsonEdge{From: ids[node], To: ids[succ]}
+			if node.Typ == nodeCondition {
+				branch := idx
+				edge.Branch = &branch
+			}
+			jg.Edges = append(jg.Edges, edge)
+		}
+	}
+	return jg
+}
+
+// Visualize generates the graph in JSON and writes to writer
+func (v *jsonVizer) Visualize(tf *TaskFlow, writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(newJSONGraph(tf.graph, "", v.keep)); err != nil {
+		return fmt.Errorf("write json output -> %w", err)
+	}
+	return nil
+}
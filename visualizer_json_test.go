@@ -0,0 +1,81 @@
+package gotaskflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+// newVisualizerTestFlow returns a flow with a priority, an instantiated subflow and a loop through a condition:
+//
+//	init -> A -> sub{S1 -> S2} -> cond -0-> B
+//	        ^-------------------------1-'
+func newVisualizerTestFlow() *TaskFlow {
+	tf := NewTaskFlow("viz")
+	init := tf.NewTask("init", func() {})
+	A := tf.NewTask("A", func() {}).Priority(HIGH)
+	sub := tf.NewSubflow("sub", func(sf *Subflow) {
+		S1 := sf.NewTask("S1", func() {})
+		S2 := sf.NewTask("S2", func() {})
+		S1.Precede(S2)
+	})
+	cond := tf.NewCondition("cond", func() uint { return 0 })
+	B := tf.NewTask("B", func() {})
+	init.Precede(A)
+	A.Precede(sub)
+	sub.Precede(cond)
+	cond.Precede(B, A)
+
+	NewExecutor(4).Run(tf).Wait()
+	return tf
+}
+
+func TestJSONVizer_Visualize(t *testing.T) {
+	tf := newVisualizerTestFlow()
+
+	var buf bytes.Buffer
+	if err := tf.DumpWith(NewJSONVisualizer(), &buf); err != nil {
+		t.Fatalf("Visualize returned an error: %v", err)
+	}
+
+	var g jsonGraph
+	if err := json.Unmarshal(buf.Bytes(), &g); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if g.Name != "viz" || len(g.Nodes) != 5 {
+		t.Fatalf("unexpected graph %+v", g)
+	}
+
+	byName := make(map[string]jsonNode)
+	for _, n := range g.Nodes {
+		byName[n.Name] = n
+	}
+	if n := byName["A"]; n.Type != "static" || n.Priority != "high" {
+		t.Errorf("unexpected node A %+v", n)
+	}
+	if n := byName["cond"]; n.Type != "condition" {
+		t.Errorf("unexpected node cond %+v", n)
+	}
+	sub := byName["sub"].Subflow
+	if sub == nil || len(sub.Nodes) != 2 || len(sub.Edges) != 1 {
+		t.Fatalf("expected subflow with 2 nodes and 1 edge, got %+v", sub)
+	}
+	if e := sub.Edges[0]; e.From != "2/0" || e.To != "2/1" || e.Branch != nil {
+		t.Errorf("unexpected subflow edge %+v", e)
+	}
+
+	branches := make(map[string]int)
+	for _, e := range g.Edges {
+		if e.From == byName["cond"].ID {
+			if e.Branch == nil {
+				t.Fatalf("condition edge without branch: %+v", e)
+			}
+			branches[e.To] = *e.Branch
+		} else if e.Branch != nil {
+			t.Errorf("static edge with branch: %+v", e)
+		}
+	}
+	if branches[byName["B"].ID] != 0 || branches[byName["A"].ID] != 1 {
+		t.Errorf("unexpected condition branches %v", branches)
+	}
+}
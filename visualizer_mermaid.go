@@ -0,0 +1,96 @@
+package gotaskflow
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type mermaidVizer struct {
+	keep nodeFilter
+}
+
+// mermaidWriter assigns every node a unique id and writes a Mermaid flowchart.
+type mermaidWriter struct {
+	sb   strings.Builder
+	ids  map[*innerNode]string
+	keep nodeFilter
+}
+
+var mermaidLabelEscaper = strings.NewReplacer(`"`, "#quot;", "\n", " ")
+
+func mermaidLabel(s string) string {
+	return `"` + mermaidLabelEscaper.Replace(s) + `"`
+}
+
+func (m *mermaidWriter) id(node *innerNode) string {
+	if id, ok := m.ids[node]; ok {
+		return id
+	}
+	id := fmt.Sprintf("n%d", len(m.ids))
+	m.ids[node] = id
+	return id
+}
+
+// visualizeG writes the nodes of g, subflows as nested subgraphs, then its edges.
+func (m *mermaidWriter) visualizeG(g *eGraph, indent string) {
+	for _, node := range g.nodes {
+		if !m.keep.visible(node) {
+			continue
+		}
+		id := m.id(node)
+		class := ""
+		if node.priority == HIGH {
+			class = ":::high"
+		} else if node.priority == LOW {
+			class = ":::low"
+		}
+
+		switch p := node.ptr.(type) {
+		case *Static:
+			m.sb.WriteString(indent + id + "[" + mermaidLabel(node.name) + "]" + class + "\n")
+		case *Condition:
+			m.sb.WriteString(indent + id + "{" + mermaidLabel(node.name) + "}" + class + "\n")
+		case *Subflow:
+			m.sb.WriteString(indent + "subgraph " + id + " [" + mermaidLabel(node.name) + "]\n")
+			m.sb.WriteString(indent + "  direction LR\n")
+			if p.g != nil {
+				m.visualizeG(p.g, indent+"  ")
+			}
+			m.sb.WriteString(indent + "end\n")
+			if class != "" {
+				m.sb.WriteString(indent + "class " + id + " " + class[3:] + "\n")
+			}
+		}
+	}
+
+	for _, node := range g.nodes {
+		_, cond := node.ptr.(*Condition)
+		for idx, succ := range node.successors {
+			if !m.keep.visible(node) || !m.keep.visible(succ) {
+				continue
+			}
+			if cond {
+				m.sb.WriteString(fmt.Sprintf("%s%s -. %d .-> %s\n", indent, m.id(node), idx, m.id(succ)))
+			} else {
+				m.sb.WriteString(indent + m.id(node) + " --> " + m.id(succ) + "\n")
+			}
+		}
+	}
+}
+
+// Visualize generates a Mermaid flowchart and writes to writer
+func (v *mermaidVizer) Visualize(tf *TaskFlow, writer io.Writer) error {
+	m := &mermaidWriter{ids: make(map[*innerNode]string), keep: v.keep}
+	m.sb.WriteString("---\ntitle: " + strconv.Quote(tf.graph.name) + "\n---\n")
+	m.sb.WriteString("flowchart LR\n")
+	m.sb.WriteString("  classDef high stroke:#f5427b\n")
+	m.sb.WriteString("  classDef low stroke:purple\n")
+	m.visualizeG(tf.graph, "  ")
+
+	if _, err := io.WriteString(writer, m.sb.String()); err != nil {
+		return fmt.Errorf("write mermaid output -> %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package gotaskflow
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMermaidVizer_Visualize(t *testing.T) {
+	tf := newVisualizerTestFlow()
+
+	var buf bytes.Buffer
+	if err := tf.DumpWith(NewMermaidVisualizer(), &buf); err != nil {
+		t.Fatalf("Visualize returned an error: %v", err)
+	}
+	result := buf.String()
+
+	expectedParts := []string{
+		"flowchart LR",
+		`n0["init"]`,
+		`n1["A"]:::high`,
+		`subgraph n2 ["sub"]`,
+		`    n3["S1"]`,
+		`    n3 --> n4`,
+		`n5{"cond"}`,
+		"n1 --> n2",
+		"n5 -. 0 .-> n6",
+		"n5 -. 1 .-> n1",
+	}
+	for _, part := range expectedParts {
+		if !strings.Contains(result, part) {
+			t.Errorf("Expected Mermaid output to contain %q, but it didn't.\nActual output:\n%s", part, result)
+		}
+	}
+}
+
+func TestMermaidLabelEscaping(t *testing.T) {
+	if got := mermaidLabel("say \"hi\"\nnow"); got != `"say #quot;hi#quot; now"` {
+		t.Errorf("unexpected label %s", got)
+	}
+}
@@ -0,0 +1,47 @@
+package gotaskflow
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFilterVisualizer(t *testing.T) {
+	tf := newVisualizerTestFlow()
+	tf.FindTask("S2").Label("db", "")
+	tf.FindTask("cond").Label("db", "")
+	tf.FindTask("B").Label("db", "")
+
+	var buf bytes.Buffer
+	if err := tf.DumpWith(NewFilterVisualizer(NewMermaidVisualizer(), Labeled("db")), &buf); err != nil {
+		t.Fatal(err)
+	}
+	mermaid := buf.String()
+	for _, part := range []string{`subgraph n0 ["sub"]`, `n1["S2"]`, `n2{"cond"}`, `n3["B"]`, "n0 --> n2", "n2 -. 0 .-> n3"} {
+		if !strings.Contains(mermaid, part) {
+			t.Errorf("expected %q in:\n%s", part, mermaid)
+		}
+	}
+	for _, name := range []string{`"init"`, `"A"`, `"S1"`} {
+		if strings.Contains(mermaid, name) {
+			t.Errorf("expected %s filtered out:\n%s", name, mermaid)
+		}
+	}
+
+	buf.Reset()
+	if err := tf.DumpWith(NewFilterVisualizer(NewDOTVisualizer(), Labeled("db")), &buf); err != nil {
+		t.Fatal(err)
+	}
+	if dot := buf.String(); strings.Contains(dot, `"viz/A"`) || !strings.Contains(dot, `"viz/cond" -> "viz/B"`) {
+		t.Errorf("unexpected filtered DOT:\n%s", dot)
+	}
+}
+
+func TestFilterVisualizerUnknown(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for a Visualizer of another package")
+		}
+	}()
+	NewFilterVisualizer(struct{ Visualizer }{}, Labeled("db"))
+}
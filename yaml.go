@@ -0,0 +1,484 @@
+package gotaskflow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// decodeYAML decodes the subset of YAML FlowSpecs need into maps, slices and strings: block mappings
+// and sequences, flow sequences and mappings spanning one or more lines, plain and quoted scalars on
+// one line, and comments. Scalars decode as strings, except null and ~ which decode as nil. Anchors,
+// tags, block scalars and multi-line scalars are rejected.
+func decodeYAML(data []byte) (any, error) {
+	lines, err := yamlLines(string(data))
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("yaml: no document")
+	}
+	p := &yamlParser{lines: lines}
+	indent := lines[0].indent
+	v, err := p.node(indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.i < len(p.lines) {
+		l := p.lines[p.i]
+		if l.indent == indent {
+			return nil, fmt.Errorf("yaml line %d: unexpected %q", l.no, l.text)
+		}
+		return nil, fmt.Errorf("yaml line %d: unexpected indentation", l.no)
+	}
+	return v, nil
+}
+
+// yamlLine is a line of a YAML document holding content.
+type yamlLine struct {
+	no     int // from 1
+	indent int
+	text   string // without indentation and comment
+}
+
+// yamlLines splits doc into its lines holding content, stripped of comments. The lines of a flow
+// collection spanning several lines are joined into the line it starts on.
+func yamlLines(doc string) ([]yamlLine, error) {
+	var (
+		lines []yamlLine
+		open  bool // whether the last line holds an unterminated flow collection
+	)
+	for i, raw := range strings.Split(doc, "\n") {
+		raw = strings.TrimRight(raw, "\r")
+		text := strings.TrimLeft(raw, " ")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		if open {
+			if text = strings.TrimSpace(stripYAMLComment(text)); text != "" {
+				last := &lines[len(lines)-1]
+				last.text += " " + text
+				open = yamlFlowOpen(last.text)
+			}
+			continue
+		}
+		if strings.HasPrefix(text, "\t") {
+			return nil, fmt.Errorf("yaml line %d: tabs cannot indent", i+1)
+		}
+		text = strings.TrimRight(stripYAMLComment(text), " \t")
+		switch {
+		case text == "" || text == "---" && len(lines) == 0:
+			continue
+		case text == "...":
+			return lines, nil
+		case strings.HasPrefix(text, "%") || text == "---":
+			return nil, fmt.Errorf("yaml line %d: directives and multiple documents are not supported", i+1)
+		}
+		lines = append(lines, yamlLine{no: i + 1, indent: len(raw) - len(strings.TrimLeft(raw, " ")), text: text})
+		open = yamlFlowOpen(text)
+	}
+	return lines, nil
+}
+
+// yamlFlowOpen tells whether text ends within a flow collection starting its value.
+func yamlFlowOpen(text string) bool {
+	for text != "" {
+		if isYAMLItem(text) {
+			text = strings.TrimLeft(text[1:], " ")
+			continue
+		}
+		if _, rest, ok, err := splitYAMLKey(yamlLine{text: text}); err == nil && ok {
+			text = rest
+			continue
+		}
+		break
+	}
+	if text == "" || text[0] != '[' && text[0] != '{' {
+		return false
+	}
+	var (
+		depth int
+		quote byte
+		last  byte // last character outside quoted scalars, besides spaces
+	)
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if quote != 0 {
+			switch {
+			case quote == '"' && c == '\\':
+				i++
+			case c == quote && quote == '\'' && i+1 < len(text) && text[i+1] == '\'':
+				i++
+			case c == quote:
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'':
+			if strings.IndexByte("[{,:", last) >= 0 {
+				quote = c
+			}
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+		}
+		if c != ' ' {
+			last = c
+		}
+	}
+	return depth > 0
+}
+
+// stripYAMLComment cuts text at a '#' starting a comment, outside quoted scalars.
+func stripYAMLComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t'):
+			return text[:i]
+		}
+	}
+	return text
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	i     int // next line
+}
+
+// node parses the block node starting at the next line, indented by indent.
+func (p *yamlParser) node(indent int) (any, error) {
+	l := p.lines[p.i]
+	if isYAMLItem(l.text) {
+		return p.sequence(indent)
+	}
+	if _, _, ok, err := splitYAMLKey(l); err != nil || ok {
+		if err != nil {
+			return nil, err
+		}
+		return p.mapping(indent)
+	}
+	p.i++
+	return yamlValue(l.text, l.no)
+}
+
+// child parses the node nested below a line indented by indent, nil if there is none.
+func (p *yamlParser) child(indent int) (any, error) {
+	if p.i < len(p.lines) && p.lines[p.i].indent > indent {
+		return p.node(p.lines[p.i].indent)
+	}
+	return nil, nil
+}
+
+func (p *yamlParser) sequence(indent int) ([]any, error) {
+	seq := []any{}
+	for p.i < len(p.lines) {
+		l := &p.lines[p.i]
+		if l.indent != indent || !isYAMLItem(l.text) {
+			break
+		}
+		rest := strings.TrimLeft(l.text[1:], " ")
+		var (
+			v   any
+			err error
+		)
+		if rest == "" {
+			p.i++
+			v, err = p.child(indent)
+		} else {
+			// the item starts on the line of its dash, parse it as if it were on a line of its own
+			l.indent += len(l.text) - len(rest)
+			l.text = rest
+			v, err = p.node(l.indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		seq = append(seq, v)
+	}
+	return seq, nil
+}
+
+func (p *yamlParser) mapping(indent int) (map[string]any, error) {
+	m := make(map[string]any)
+	for p.i < len(p.lines) {
+		l := p.lines[p.i]
+		if l.indent != indent {
+			break
+		}
+		key, rest, ok, err := splitYAMLKey(l)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("yaml line %d: expected a key", l.no)
+		}
+		if _, dup := m[key]; dup {
+			return nil, fmt.Errorf("yaml line %d: duplicate key %q", l.no, key)
+		}
+		p.i++
+
+		var v any
+		switch {
+		case rest != "":
+			v, err = yamlValue(rest, l.no)
+		case p.i < len(p.lines) && p.lines[p.i].indent == indent && isYAMLItem(p.lines[p.i].text):
+			// a sequence may be indented as its key
+			v, err = p.sequence(indent)
+		default:
+			v, err = p.child(indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		m[key] = v
+	}
+	return m, nil
+}
+
+func isYAMLItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitYAMLKey splits a "key: value" line, ok is false if l holds no key.
+func splitYAMLKey(l yamlLine) (key, rest string, ok bool, err error) {
+	text := l.text
+	if text[0] == '"' || text[0] == '\'' {
+		f := &yamlFlow{s: text, no: l.no}
+		k, err := f.quoted()
+		if err != nil {
+			return "", "", false, err
+		}
+		after := strings.TrimLeft(text[f.pos:], " ")
+		if after != ":" && !strings.HasPrefix(after, ": ") {
+			return "", "", false, nil
+		}
+		return k, strings.TrimSpace(after[1:]), true, nil
+	}
+	if text[0] == '[' || text[0] == '{' || isYAMLItem(text) {
+		return "", "", false, nil
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] == ':' && (i == len(text)-1 || text[i+1] == ' ') {
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), true, nil
+		}
+	}
+	return "", "", false, nil
+}
+
+// yamlValue decodes the value on the line no, a scalar or a flow collection.
+func yamlValue(s string, no int) (any, error) {
+	f := &yamlFlow{s: s, no: no}
+	var (
+		v   any
+		err error
+	)
+	switch s[0] {
+	case '[', '{', '"', '\'':
+		if v, err = f.value(); err != nil {
+			return nil, err
+		}
+	case '|', '>', '&', '*', '!', '%', '@', '`':
+		return nil, fmt.Errorf("yaml line %d: %q is not supported", no, s[0])
+	default:
+		return plainYAMLScalar(s), nil
+	}
+	if f.space(); f.pos < len(s) {
+		return nil, fmt.Errorf("yaml line %d: unexpected %q", no, s[f.pos:])
+	}
+	return v, nil
+}
+
+func plainYAMLScalar(s string) any {
+	switch s {
+	case "null", "Null", "NULL", "~":
+		return nil
+	}
+	return s
+}
+
+// yamlFlow parses the flow collections and quoted scalars of a line.
+type yamlFlow struct {
+	s   string
+	pos int
+	no  int
+}
+
+func (f *yamlFlow) space() {
+	for f.pos < len(f.s) && f.s[f.pos] == ' ' {
+		f.pos++
+	}
+}
+
+func (f *yamlFlow) errorf(format string, args ...any) error {
+	return fmt.Errorf("yaml line %d: %s", f.no, fmt.Sprintf(format, args...))
+}
+
+func (f *yamlFlow) value() (any, error) {
+	f.space()
+	if f.pos == len(f.s) {
+		return nil, f.errorf("missing value")
+	}
+	switch f.s[f.pos] {
+	case '[':
+		return f.sequence()
+	case '{':
+		return f.mapping()
+	case '"', '\'':
+		return f.quoted()
+	}
+	return plainYAMLScalar(f.plain()), nil
+}
+
+// plain reads a plain scalar of a flow collection.
+func (f *yamlFlow) plain() string {
+	start := f.pos
+	for f.pos < len(f.s) && !strings.ContainsRune(",[]{}", rune(f.s[f.pos])) {
+		if f.s[f.pos] == ':' && (f.pos+1 == len(f.s) || strings.ContainsRune(" ,[]{}", rune(f.s[f.pos+1]))) {
+			break
+		}
+		f.pos++
+	}
+	return strings.TrimSpace(f.s[start:f.pos])
+}
+
+func (f *yamlFlow) quoted() (string, error) {
+	q := f.s[f.pos]
+	for i := f.pos + 1; i < len(f.s); i++ {
+		switch {
+		case q == '"' && f.s[i] == '\\':
+			i++
+		case f.s[i] == q && q == '\'' && i+1 < len(f.s) && f.s[i+1] == '\'':
+			i++
+		case f.s[i] == q:
+			raw := f.s[f.pos : i+1]
+			f.pos = i + 1
+			if q == '\'' {
+				return strings.ReplaceAll(raw[1:len(raw)-1], "''", "'"), nil
+			}
+			s, ok := unescapeYAML(raw[1 : len(raw)-1])
+			if !ok {
+				return "", f.errorf("invalid quoted scalar %s", raw)
+			}
+			return s, nil
+		}
+	}
+	return "", f.errorf("unterminated quoted scalar")
+}
+
+// yamlEscapes maps the single character escapes of double-quoted scalars to what they stand for.
+var yamlEscapes = map[byte]rune{
+	'0': 0, 'a': '\a', 'b': '\b', 't': '\t', '\t': '\t', 'n': '\n', 'v': '\v', 'f': '\f', 'r': '\r',
+	'e': 0x1b, ' ': ' ', '"': '"', '/': '/', '\\': '\\', 'N': 0x85, '_': 0xa0, 'L': 0x2028, 'P': 0x2029,
+}
+
+// unescapeYAML decodes the escape sequences of the double-quoted scalar s, given without its quotes.
+// ok is false if s holds an escape sequence YAML does not define.
+func unescapeYAML(s string) (_ string, ok bool) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i++; i == len(s) {
+			return "", false
+		}
+		if r, ok := yamlEscapes[s[i]]; ok {
+			b.WriteRune(r)
+			continue
+		}
+		var n int // hex digits of the code point
+		switch s[i] {
+		case 'x':
+			n = 2
+		case 'u':
+			n = 4
+		case 'U':
+			n = 8
+		}
+		if n == 0 || i+n >= len(s) {
+			return "", false
+		}
+		r, err := strconv.ParseUint(s[i+1:i+1+n], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(r)) {
+			return "", false
+		}
+		b.WriteRune(rune(r))
+		i += n
+	}
+	return b.String(), true
+}
+
+// collection parses the items of a flow collection opened at f.pos and closed by end.
+func (f *yamlFlow) collection(end byte, item func() error) error {
+	f.pos++
+	for {
+		f.space()
+		if f.pos < len(f.s) && f.s[f.pos] == end {
+			f.pos++
+			return nil
+		}
+		if err := item(); err != nil {
+			return err
+		}
+		f.space()
+		switch {
+		case f.pos == len(f.s):
+			return f.errorf("unterminated flow collection")
+		case f.s[f.pos] == ',':
+			f.pos++
+		case f.s[f.pos] != end:
+			return f.errorf("expected ',' or %q, got %q", end, f.s[f.pos])
+		}
+	}
+}
+
+func (f *yamlFlow) sequence() ([]any, error) {
+	seq := []any{}
+	err := f.collection(']', func() error {
+		v, err := f.value()
+		seq = append(seq, v)
+		return err
+	})
+	return seq, err
+}
+
+func (f *yamlFlow) mapping() (map[string]any, error) {
+	m := make(map[string]any)
+	err := f.collection('}', func() error {
+		var (
+			key string
+			err error
+		)
+		if c := f.s[f.pos]; c == '"' || c == '\'' {
+			if key, err = f.quoted(); err != nil {
+				return err
+			}
+		} else {
+			key = f.plain()
+		}
+		f.space()
+		if f.pos == len(f.s) || f.s[f.pos] != ':' {
+			return f.errorf("expected ':' after key %q", key)
+		}
+		f.pos++
+		if _, dup := m[key]; dup {
+			return f.errorf("duplicate key %q", key)
+		}
+		m[key], err = f.value()
+		return err
+	})
+	return m, err
+}
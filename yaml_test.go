@@ -0,0 +1,63 @@
+package gotaskflow
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeYAML(t *testing.T) {
+	for doc, want := range map[string]any{
+		"a: 1\nb: x y # comment\n":                    map[string]any{"a": "1", "b": "x y"},
+		"---\nlist:\n- a\n-   b\n...\nignored":        map[string]any{"list": []any{"a", "b"}},
+		"- - a\n  - b\n- c":                           []any{[]any{"a", "b"}, "c"},
+		"a:\n  b:\n    c: ~\n":                        map[string]any{"a": map[string]any{"b": map[string]any{"c": nil}}},
+		"a: [x, 'y''s', \"z # \\\"q\\\"\"]":           map[string]any{"a": []any{"x", "y's", `z # "q"`}},
+		"a: {b: [1, {c: d}], 'e': []}":                map[string]any{"a": map[string]any{"b": []any{"1", map[string]any{"c": "d"}}, "e": []any{}}},
+		"- a: 1\n  b:\n  - c\n- d: 2":                 []any{map[string]any{"a": "1", "b": []any{"c"}}, map[string]any{"d": "2"}},
+		"\"quoted key\": http://host:80/path":         map[string]any{"quoted key": "http://host:80/path"},
+		"{a: [b,\n  c], # comment\n d: {e: f}\n}":     map[string]any{"a": []any{"b", "c"}, "d": map[string]any{"e": "f"}},
+		"a: [\n  b, 'it''s ]',\n  \"c]\"\n]\nd: e":    map[string]any{"a": []any{"b", "it's ]", "c]"}, "d": "e"},
+		"- [a,\n   b]\n- c":                           []any{[]any{"a", "b"}, "c"},
+		`a: "\/\\\"\x41\u00e9\U0001F600\t\e\0\_\N\L"`: map[string]any{"a": "/\\\"A\u00e9\U0001F600\t\x1b\x00\u00a0\u0085\u2028"},
+	} {
+		got, err := decodeYAML([]byte(doc))
+		if err != nil {
+			t.Errorf("decode %q: %v", doc, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("decode %q: got %#v, want %#v", doc, got, want)
+		}
+	}
+}
+
+func TestDecodeYAMLErrors(t *testing.T) {
+	for doc, want := range map[string]string{
+		"":                    "no document",
+		"a: 1\n\tb: 2":        "line 2: tabs cannot indent",
+		"a: 1\na: 2":          `line 2: duplicate key "a"`,
+		"a: 1\n  b: 2":        "line 2: unexpected indentation",
+		"a: 1\n- b":           "line 2: expected a key",
+		"a: |\n  text":        "line 1: '|' is not supported",
+		"a: &x 1":             "line 1: '&' is not supported",
+		"a: [b, c":            "line 1: unterminated flow collection",
+		"a: \"b":              "line 1: unterminated quoted scalar",
+		"a: [b] c":            `line 1: unexpected "c"`,
+		"a: 1\n---\nb: 2":     "line 2: directives and multiple documents are not supported",
+		"a: {b c}":            `line 1: expected ':' after key "b c"`,
+		"a: 1\nb\n":           "line 2: expected a key",
+		"- a\nb: 1":           `line 2: unexpected "b: 1"`,
+		"a: [b, c]\n  d: [e]": "line 2: unexpected indentation",
+		"a: [b,\n  c":         "line 1: unterminated flow collection",
+		`a: "\q"`:             `line 1: invalid quoted scalar "\q"`,
+		`a: "\101"`:           `line 1: invalid quoted scalar "\101"`,
+		`a: "\x4"`:            `line 1: invalid quoted scalar "\x4"`,
+		`a: "\ud800"`:         `line 1: invalid quoted scalar "\ud800"`,
+	} {
+		_, err := decodeYAML([]byte(doc))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("decode %q: expected error %q, got %v", doc, want, err)
+		}
+	}
+}